@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// BootstrapAdmins makes the accounts with the given emails admins, existing
+// ones right away and others when they register, so that a fresh install
+// has someone who can assign roles. Emails are compared ignoring case.
+func (s *APIServer) BootstrapAdmins(emails []string) error {
+	s.adminEmails = map[string]bool{}
+	for _, email := range emails {
+		s.adminEmails[strings.ToLower(email)] = true
+	}
+
+	users, err := s.db.ListUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if s.isBootstrapAdmin(user.Email) && user.Role != types.RoleAdmin {
+			if err := s.db.UpdateUserRole(user.ID, types.RoleAdmin); err != nil {
+				return err
+			}
+			logger.Info("Promoted bootstrap admin", "user_id", user.ID)
+		}
+	}
+	return nil
+}
+
+func (s *APIServer) isBootstrapAdmin(email string) bool {
+	return s.adminEmails[strings.ToLower(email)]
+}
+
+func (s *APIServer) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.db.ListUsers()
+	if err != nil {
+		writeError(w, r, err, "Failed to fetch users")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// handleUpdateUserRole promotes or demotes a user. The new role is embedded
+// in the user's tokens the next time they are issued.
+func (s *APIServer) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req types.UpdateRoleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if !types.IsValidRole(req.Role) {
+		writeProblem(w, r, "Unknown role, use one of "+strings.Join(types.Roles, ", "), http.StatusBadRequest)
+		return
+	}
+
+	// Stops the last admin from locking everyone out by accident
+	caller, _ := middleware.UserFromContext(r.Context())
+	if caller.ID == id {
+		writeProblem(w, r, "You can't change your own role", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateUserRole(id, req.Role); err != nil {
+		writeError(w, r, err, "Failed to update role")
+		return
+	}
+
+	user, err := s.db.GetUserByID(id)
+	if err != nil {
+		writeError(w, r, err, "Failed to fetch user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,35 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func TestBootstrapAdmins(t *testing.T) {
+	a := newTestAPI(t)
+	_, existing := a.register("first@example.com")
+
+	server := api.NewAPIServer(a.db, testSecret)
+	if err := server.BootstrapAdmins([]string{"First@Example.com", "later@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	a.handler = server.Handler()
+
+	if user, _ := a.db.GetUserByID(existing.ID); user.Role != types.RoleAdmin {
+		t.Errorf("existing account has role %q, want admin", user.Role)
+	}
+
+	token, later := a.register("later@example.com")
+	if later.Role != types.RoleAdmin {
+		t.Errorf("registered account has role %q, want admin", later.Role)
+	}
+	expectStatus(t, a.do("GET", "/api/admin/users", token, nil), http.StatusOK)
+
+	_, other := a.register("other@example.com")
+	if other.Role != types.RoleStudent {
+		t.Errorf("unlisted account has role %q, want student", other.Role)
+	}
+}
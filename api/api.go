@@ -2,11 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
-    "net"
-	"fmt"
-	"log"
 
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
 	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
 	"github.com/gorilla/mux"
 	"github.com/rizkyswandy/TeamSeekerBackend/types"
@@ -16,43 +16,148 @@ type APIServer struct {
 	router *mux.Router
 	db     Database
 	jwtSecret []byte
+	// adminEmails are made admins when they register, see BootstrapAdmins
+	adminEmails map[string]bool
 }
 
 type StudentProfile struct {
 	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Email        string   `json:"email"`
-	Faculty      string   `json:"faculty"`
-	FieldOfStudy string   `json:"field_of_study"`
-	Semester     int      `json:"semester"`
-	Skills       []string `json:"skills"`
-	Focus        []string `json:"focus"`
-	IsAvailable  bool     `json:"is_available"`
-	CreatedAt    string   `json:"created_at"`
-	UpdatedAt    string   `json:"updated_at"`
+	UserID       string   `json:"user_id"`
+	Name         string   `json:"name" validate:"required,max=100"`
+	Email        string   `json:"email" validate:"required,email,max=254"`
+	Faculty      string   `json:"faculty" validate:"required,max=100"`
+	FieldOfStudy string   `json:"field_of_study" validate:"required,max=100"`
+	Semester     int      `json:"semester" validate:"min=1,max=14"`
+	Skills       []string `json:"skills" validate:"max=30,itemmax=50"`
+	Focus        []string `json:"focus" validate:"max=10,itemmax=50"`
+	Bio          string   `json:"bio" validate:"max=2000"`
+	// SkillLevels holds the proficiency of some or all of Skills
+	SkillLevels []SkillLevel `json:"skill_levels" validate:"max=30"`
+	// Endorsements counts the endorsements of each skill by other users, it
+	// can't be set by the client
+	Endorsements map[string]int `json:"endorsements"`
+	IsAvailable  bool           `json:"is_available"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+	// Version is incremented by every update, it is also sent as the ETag
+	Version int `json:"version"`
+	// Match is only set on the results of a search with q
+	Match *SearchMatch `json:"match,omitempty"`
 }
 
+// Match modes for the list filters of SearchFilters
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
 type SearchFilters struct {
-	Faculty      string   `json:"faculty"`
-	Skills       []string `json:"skills"`
-	Focus        []string `json:"focus"`
-	Availability bool     `json:"availability"`
+	// Query is matched against name, field of study, skills, focus and bio
+	Query         string   `json:"q"`
+	Faculty       string   `json:"faculty"`
+	FieldOfStudy  string   `json:"field_of_study"`
+	Skills        []string `json:"skills"`
+	SkillsMatch   string   `json:"skills_match"`
+	ExcludeSkills []string `json:"exclude_skills"`
+	Focus         []string `json:"focus"`
+	FocusMatch    string   `json:"focus_match"`
+	ExcludeFocus  []string `json:"exclude_focus"`
+	// Availability is nil to match available and unavailable students alike
+	Availability *bool `json:"availability"`
+	SemesterMin  int   `json:"semester_min"`
+	SemesterMax  int   `json:"semester_max"`
+	// MinProficiency restricts Skills to students with at least this level
+	// in them, per SkillsMatch
+	MinProficiency string `json:"min_proficiency"`
 }
 
 type Database interface {
 	CreateProfile(profile *StudentProfile) error
 	GetProfile(id string) (StudentProfile, error)
-	UpdateProfile(id string, profile *StudentProfile) error
+	GetProfileByUserID(userID string) (StudentProfile, error)
+	// UpdateProfile stores the editable fields of profile and reloads it. With
+	// a non-zero version it fails with apperr.ErrPreconditionFailed unless
+	// the stored profile still has that version.
+	UpdateProfile(id string, profile *StudentProfile, version int) error
 	DeleteProfile(id string) error
 
 	// MARK: Search Operations
-	SearchProfiles(filter SearchFilters) ([]StudentProfile, error)
-	GetAllProfiles() ([]StudentProfile, error)
+	SearchProfiles(filter SearchFilters, opts ListOptions) (ProfilePage, error)
+	GetAllProfiles(opts ListOptions) (ProfilePage, error)
+	// ProfileFacets counts the profiles matching filter per faculty, field
+	// of study, semester and availability, and for the limit most common
+	// skills and focus areas
+	ProfileFacets(filter SearchFilters, limit int) (Facets, error)
+	// FindMatchCandidates returns the limit profiles sharing at least one of
+	// the skills or focus areas with the highest MatchQuery.Score, the most
+	// recently updated first on equal scores
+	FindMatchCandidates(query MatchQuery, limit int) ([]StudentProfile, error)
+
+	// MARK: Teams
+	// CreateTeam stores the team and adds ownerProfileID as its first member
+	CreateTeam(team *Team, ownerProfileID string) error
+	GetTeam(id string) (Team, error)
+	// UpdateTeam fails with a conflict if the team has more members than
+	// the new max size
+	UpdateTeam(id string, team *Team) error
+	DeleteTeam(id string) error
+	SearchTeams(filter TeamSearchFilters, opts ListOptions) (TeamPage, error)
+	GetTeamMembers(teamID string) ([]TeamMember, error)
+	RemoveTeamMember(teamID string, profileID string) error
+
+	// MARK: Invitations and join requests
+	CreateTeamRequest(req *TeamRequest) error
+	GetTeamRequest(id string) (TeamRequest, error)
+	// TransitionTeamRequest moves a pending request to status. Accepting adds
+	// the profile to the team unless that would exceed its max size.
+	// Transitioning to the current status is a no-op.
+	TransitionTeamRequest(id string, status string) (TeamRequest, error)
+	ListTeamRequests(filter TeamRequestFilter) ([]TeamRequest, error)
+
+	// MARK: Taxonomy
+	CreateTerm(term *Term) error
+	GetTerm(id string) (Term, error)
+	UpdateTerm(id string, term *Term) error
+	DeleteTerm(id string) error
+	// SearchTerms returns the terms of kind whose name, alias or synonym
+	// starts with prefix, ignoring case, exact and name matches first. A
+	// limit of 0 returns every match.
+	SearchTerms(kind string, prefix string, limit int) ([]Term, error)
+	TermLookup(kind string) (TermLookup, error)
+	// MergeTerms folds source into target: the name, aliases and synonyms of
+	// source become aliases and synonyms of target, source is deleted and
+	// profiles and teams are re-tagged.
+	MergeTerms(sourceID string, targetID string) (MergeResult, error)
+	// Retag rewrites the skills and focus of every profile and team to their
+	// canonical names and returns the number of each changed
+	Retag() (RetagResult, error)
+
+	// MARK: Endorsements
+	// EndorseSkill records that userID endorses skill on the profile and
+	// returns the number of endorsements of the skill. Endorsing twice is a
+	// no-op.
+	EndorseSkill(profileID string, skill string, userID string) (int, error)
+	// RevokeEndorsement removes the endorsement, apperr.ErrNotFound if there
+	// is none
+	RevokeEndorsement(profileID string, skill string, userID string) (int, error)
 
 	// MARK: Auth methods goes here
 	CreateUser(user *types.User) error
 	GetUserByEmail(email string) (types.User, error)
 	GetUserByID(id string) (types.User, error)
+	ListUsers() ([]types.User, error)
+	UpdateUserRole(id string, role string) error
+
+	// MARK: Refresh tokens
+	CreateRefreshToken(token *types.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (types.RefreshToken, error)
+	RotateRefreshToken(oldID string, next *types.RefreshToken) error
+	RevokeRefreshTokenFamily(familyID string) error
+	RevokeUserRefreshTokens(userID string) error
+
+	// MARK: Lifecycle
+	// Close releases the connections of the store once the server stopped
+	Close() error
 }
 
 func NewAPIServer(db Database, jwtSecret []byte) *APIServer {
@@ -65,34 +170,117 @@ func NewAPIServer(db Database, jwtSecret []byte) *APIServer {
     return server
 }
 
-func (s *APIServer) setupRoutes() {
+// route describes one endpoint. Routes with an empty permission are public,
+// every other route requires a valid access token whose role grants it.
+type route struct {
+	method     string
+	path       string
+	handler    http.HandlerFunc
+	permission types.Permission
+}
 
-	s.router.Use(middleware.Logger)
-    s.router.Use(middleware.CORS)
+// routes is the permission table of the API. Order matters: static paths
+// like /api/profiles/search must come before /api/profiles/{id}.
+func (s *APIServer) routes() []route {
+	return []route{
+		{"POST", "/api/auth/register", s.handleRegister, ""},
+		{"POST", "/api/auth/login", s.handleLogin, ""},
+		{"POST", "/api/auth/refresh", s.handleRefresh, ""},
+		{"POST", "/api/auth/logout", s.handleLogout, ""},
+		{"POST", "/api/auth/logout-all", s.handleLogoutAll, types.PermAccountManage},
+
+		{"POST", "/api/profiles", s.handleCreateProfile, types.PermProfileWrite},
+		{"GET", "/api/profiles", s.handleGetAllProfiles, types.PermProfileRead},
+		{"GET", "/api/profiles/search", s.handleSearchProfiles, types.PermProfileRead},
+		{"POST", "/api/profiles/search", s.handleSearchProfilesJSON, types.PermProfileRead},
+		{"POST", "/api/profiles/match", s.handleMatchProfiles, types.PermProfileRead},
+		{"GET", "/api/profiles/{id}", s.handleGetProfile, types.PermProfileRead},
+		{"PUT", "/api/profiles/{id}", s.handleUpdateProfile, types.PermProfileWrite},
+		{"PATCH", "/api/profiles/{id}", s.handlePatchProfile, types.PermProfileWrite},
+		{"DELETE", "/api/profiles/{id}", s.handleDeleteProfile, types.PermProfileWrite},
+		{"PUT", "/api/profiles/{id}/endorsements/{skill:.+}", s.handleEndorseSkill, types.PermProfileEndorse},
+		{"DELETE", "/api/profiles/{id}/endorsements/{skill:.+}", s.handleRevokeEndorsement, types.PermProfileEndorse},
+
+		{"POST", "/api/teams", s.handleCreateTeam, types.PermTeamManage},
+		{"GET", "/api/teams", s.handleGetAllTeams, types.PermTeamRead},
+		{"GET", "/api/teams/search", s.handleSearchTeams, types.PermTeamRead},
+		{"GET", "/api/teams/{id}", s.handleGetTeam, types.PermTeamRead},
+		{"PUT", "/api/teams/{id}", s.handleUpdateTeam, types.PermTeamManage},
+		{"DELETE", "/api/teams/{id}", s.handleDeleteTeam, types.PermTeamManage},
+		{"GET", "/api/teams/{id}/members", s.handleGetTeamMembers, types.PermTeamRead},
+		{"DELETE", "/api/teams/{id}/members/{profileID}", s.handleRemoveTeamMember, types.PermTeamJoin},
+		{"POST", "/api/teams/{id}/invitations", s.handleCreateInvitation, types.PermTeamManage},
+		{"POST", "/api/teams/{id}/join-requests", s.handleCreateJoinRequest, types.PermTeamJoin},
+		{"POST", "/api/team-requests/{id}/{action:accept|decline|withdraw}", s.handleTeamRequestAction, types.PermTeamJoin},
+		{"GET", "/api/me/team-requests/{direction:incoming|outgoing}", s.handleListTeamRequests, types.PermTeamJoin},
+
+		{"GET", "/api/skills", s.handleSearchTerms, types.PermProfileRead},
+
+		{"GET", "/api/admin/users", s.handleListUsers, types.PermUserManage},
+		{"PUT", "/api/admin/users/{id}/role", s.handleUpdateUserRole, types.PermUserManage},
+		{"POST", "/api/admin/skills", s.handleCreateTerm, types.PermTaxonomyManage},
+		{"POST", "/api/admin/skills/retag", s.handleRetag, types.PermTaxonomyManage},
+		{"PUT", "/api/admin/skills/{id}", s.handleUpdateTerm, types.PermTaxonomyManage},
+		{"DELETE", "/api/admin/skills/{id}", s.handleDeleteTerm, types.PermTaxonomyManage},
+		{"POST", "/api/admin/skills/{id}/merge", s.handleMergeTerms, types.PermTaxonomyManage},
+	}
+}
 
-	s.router.HandleFunc("/api/auth/register", s.handleRegister).Methods("POST")
-    s.router.HandleFunc("/api/auth/login", s.handleLogin).Methods("POST")
+func (s *APIServer) setupRoutes() {
+	requireAuth := middleware.Auth(s.jwtSecret)
+
+	for _, rt := range s.routes() {
+		var handler http.Handler = rt.handler
+		if rt.permission != "" {
+			handler = requireAuth(middleware.RequirePermission(rt.permission)(handler))
+		}
+		s.router.Handle(rt.path, handler).Methods(rt.method)
+	}
 
-	s.router.HandleFunc("/api/profiles", s.handleCreateProfile).Methods("POST")
-	s.router.HandleFunc("/api/profiles", s.handleGetAllProfiles).Methods("GET")
-	s.router.HandleFunc("/api/profiles/search", s.handleSearchProfiles).Methods("GET")
-	s.router.HandleFunc("/api/profiles/{id}", s.handleGetProfile).Methods("GET")
-	s.router.HandleFunc("/api/profiles/{id}", s.handleUpdateProfile).Methods("PUT")
-	s.router.HandleFunc("/api/profiles/{id}", s.handleDeleteProfile).Methods("DELETE")
+	s.router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, "No route matches "+r.URL.Path, http.StatusNotFound)
+	})
+	s.router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, r.Method+" is not allowed on "+r.URL.Path, http.StatusMethodNotAllowed)
+	})
 }
 
 func (s *APIServer) handleCreateProfile(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.UserFromContext(r.Context())
+
 	var newProfile StudentProfile
-	if err := json.NewDecoder(r.Body).Decode(&newProfile); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &newProfile); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+	if err := newProfile.validate(); err != nil {
+		writeError(w, r, err, "Invalid profile")
+		return
+	}
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to create profile")
+		return
+	}
+	terms.canonicalizeProfile(&newProfile)
+
+	// The owner always comes from the token, never from the body
+	newProfile.UserID = user.ID
+
+	if _, err := s.db.GetProfileByUserID(user.ID); err == nil {
+		writeProblem(w, r, "User already has a profile", http.StatusConflict)
+		return
+	} else if !errors.Is(err, apperr.ErrNotFound) {
+		writeError(w, r, err, "Failed to create profile")
 		return
 	}
 
 	if err := s.db.CreateProfile(&newProfile); err != nil {
-		http.Error(w, "Failed to create profile", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to create profile")
 		return
 	}
 
+	w.Header().Set("ETag", profileETag(newProfile.Version))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
@@ -104,20 +292,17 @@ func (s *APIServer) handleGetProfile(w http.ResponseWriter, r *http.Request) {
     id := vars["id"]
 
     if id == "" {
-        http.Error(w, "Profile ID is required", http.StatusBadRequest)
+        writeProblem(w, r, "Profile ID is required", http.StatusBadRequest)
         return
     }
 
     profile, err := s.db.GetProfile(id)
     if err != nil {
-        if err.Error() == "profile not found" {
-            http.Error(w, "Profile not found", http.StatusNotFound)
-            return
-        }
-        http.Error(w, "Failed to get profile", http.StatusInternalServerError)
+        writeError(w, r, err, "Failed to get profile")
         return
     }
 
+    w.Header().Set("ETag", profileETag(profile.Version))
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(profile)
 }
@@ -127,86 +312,244 @@ func (s *APIServer) handleUpdateProfile(w http.ResponseWriter, r *http.Request)
     id := vars["id"]
 
     if id == "" {
-        http.Error(w, "Profile ID is required", http.StatusBadRequest)
+        writeProblem(w, r, "Profile ID is required", http.StatusBadRequest)
+        return
+    }
+
+    existing, ok := s.authorizeProfileWrite(w, r, id)
+    if !ok {
+        return
+    }
+
+    version, err := ifMatchVersion(r, existing.Version)
+    if err != nil {
+        writeError(w, r, err, "Failed to update profile")
         return
     }
 
     var updatedProfile StudentProfile
-    if err := json.NewDecoder(r.Body).Decode(&updatedProfile); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+    if err := decodeJSON(w, r, &updatedProfile); err != nil {
+        writeError(w, r, err, "Invalid request body")
+        return
+    }
+    if err := updatedProfile.validate(); err != nil {
+        writeError(w, r, err, "Invalid profile")
+        return
+    }
+    terms, err := s.loadTermLookups()
+    if err != nil {
+        writeError(w, r, err, "Failed to update profile")
         return
     }
+    terms.canonicalizeProfile(&updatedProfile)
+
+    // Ownership can't be transferred through an update
+    updatedProfile.ID = existing.ID
+    updatedProfile.UserID = existing.UserID
 
-    if err := s.db.UpdateProfile(id, &updatedProfile); err != nil {
-        if err.Error() == "profile not found" {
-            http.Error(w, "Profile not found", http.StatusNotFound)
-            return
-        }
-        http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+    if err := s.db.UpdateProfile(id, &updatedProfile, version); err != nil {
+        writeError(w, r, err, "Failed to update profile")
         return
     }
 
+    w.Header().Set("ETag", profileETag(updatedProfile.Version))
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
     json.NewEncoder(w).Encode(updatedProfile)
 }
 
+// handlePatchProfile applies a JSON Merge Patch, so that clients only send
+// the fields they change. A field set to null is reset.
+func (s *APIServer) handlePatchProfile(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+			writeProblem(w, r, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	existing, ok := s.authorizeProfileWrite(w, r, id)
+	if !ok {
+		return
+	}
+
+	// With If-Match the client only wants to patch the version it has seen
+	conditional, err := ifMatchVersion(r, existing.Version)
+	if err != nil {
+		writeError(w, r, err, "Failed to update profile")
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := decodeJSON(w, r, &patch); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to update profile")
+		return
+	}
+
+	// The patched profile is only written if existing is still the stored
+	// version, so that concurrent patches of other fields aren't lost.
+	// Without If-Match the patch is applied again to the newer version.
+	for attempt := 1; ; attempt++ {
+		patched, err := applyMergePatch(existing, patch)
+		if err != nil {
+			writeError(w, r, err, "Failed to update profile")
+			return
+		}
+		if err := patched.validate(); err != nil {
+			writeError(w, r, err, "Invalid profile")
+			return
+		}
+		terms.canonicalizeProfile(&patched)
+
+		err = s.db.UpdateProfile(id, &patched, existing.Version)
+		if err == nil {
+			w.Header().Set("ETag", profileETag(patched.Version))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(patched)
+			return
+		}
+		if !errors.Is(err, apperr.ErrPreconditionFailed) || conditional != 0 || attempt == maxPatchAttempts {
+			writeError(w, r, err, "Failed to update profile")
+			return
+		}
+
+		if existing, ok = s.authorizeProfileWrite(w, r, id); !ok {
+			return
+		}
+	}
+}
+
 func (s *APIServer) handleDeleteProfile(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	if id == "" {
-		http.Error(w, "Profile ID is required", http.StatusBadRequest)
+		writeProblem(w, r, "Profile ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := s.authorizeProfileWrite(w, r, id); !ok {
 		return
 	}
 
 	if err := s.db.DeleteProfile(id); err != nil {
-		http.Error(w, "Failed to delete profile", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to delete profile")
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// authorizeProfileWrite loads the profile and checks that the caller owns it
+// or is allowed to moderate any profile. It writes the error response itself and reports whether the
+// handler may continue.
+func (s *APIServer) authorizeProfileWrite(w http.ResponseWriter, r *http.Request, id string) (StudentProfile, bool) {
+	profile, err := s.db.GetProfile(id)
+	if err != nil {
+		writeError(w, r, err, "Failed to get profile")
+		return StudentProfile{}, false
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	isOwner := profile.UserID != "" && profile.UserID == user.ID
+	if !isOwner && !types.HasPermission(user.Role, types.PermProfileModerate) {
+		writeProblem(w, r, "You are not allowed to modify this profile", http.StatusForbidden)
+		return StudentProfile{}, false
+	}
+
+	return profile, true
+}
+
 func (s *APIServer) handleGetAllProfiles(w http.ResponseWriter, r *http.Request) {
-	profiles, err := s.db.GetAllProfiles()
+	opts, err := parseSearchListOptions(r.URL.Query(), SearchFilters{})
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	page, err := s.db.GetAllProfiles(opts)
 	if err != nil {
-		http.Error(w, "Failed to fetch profiels!", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to fetch profiles")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(profiles)
+	json.NewEncoder(w).Encode(page)
 }
 
+// handleSearchProfiles takes the filters from the query string so that
+// searches can be bookmarked and cached
 func (s *APIServer) handleSearchProfiles(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseSearchFilters(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	s.searchProfiles(w, r, filters)
+}
+
+// handleSearchProfilesJSON is the POST variant for queries that are too
+// complex for a query string. Pagination still comes from the query string.
+func (s *APIServer) handleSearchProfilesJSON(w http.ResponseWriter, r *http.Request) {
 	var filters SearchFilters
-	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &filters); err != nil {
+		writeError(w, r, err, "Invalid request body")
 		return
 	}
 
-	profiles, err := s.db.SearchProfiles(filters)
+	if err := filters.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	s.searchProfiles(w, r, filters)
+}
+
+func (s *APIServer) searchProfiles(w http.ResponseWriter, r *http.Request, filters SearchFilters) {
+	opts, err := parseSearchListOptions(r.URL.Query(), filters)
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	terms, err := s.loadTermLookups()
 	if err != nil {
-		http.Error(w, "Failed to search profiles", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to search profiles")
 		return
 	}
+	terms.canonicalizeFilters(&filters)
+
+	facetLimit, err := parseFacetLimit(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	page, err := s.db.SearchProfiles(filters, opts)
+	if err != nil {
+		writeError(w, r, err, "Failed to search profiles")
+		return
+	}
+
+	result := ProfileSearchPage{ProfilePage: page}
+	if facetLimit > 0 {
+		facets, err := s.db.ProfileFacets(filters, facetLimit)
+		if err != nil {
+			writeError(w, r, err, "Failed to search profiles")
+			return
+		}
+		result.Facets = &facets
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(profiles)
+	json.NewEncoder(w).Encode(result)
 }
-
-func (s *APIServer) Start(addr string) error {
-    // Create server with dual-stack support (ipv4 and ipv6 support)
-    server := &http.Server{
-        Addr:    addr,
-        Handler: s.router,
-    }
-    
-    ln, err := net.Listen("tcp4", addr)
-    if err != nil {
-        return fmt.Errorf("failed to create IPv4 listener: %v", err)
-    }
-    
-    log.Printf("Server listening on %s", addr)
-    return server.Serve(ln)
-}
\ No newline at end of file
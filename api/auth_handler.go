@@ -1,30 +1,46 @@
 package api
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+    "github.com/rizkyswandy/TeamSeekerBackend/middleware"
     "github.com/rizkyswandy/TeamSeekerBackend/types"
 )
 
+const (
+    accessTokenTTL  = 15 * time.Minute
+    refreshTokenTTL = 30 * 24 * time.Hour
+)
+
 func (s *APIServer) handleRegister(w http.ResponseWriter, r *http.Request) {
     var req types.RegisterRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+    if err := decodeJSON(w, r, &req); err != nil {
+        writeError(w, r, err, "Invalid request body")
         return
     }
 
-    if req.Email == "" || req.Password == "" {
-        http.Error(w, "Email and password are required", http.StatusBadRequest)
+    req.Email = strings.TrimSpace(req.Email)
+    if err := validate.Struct(&req); err != nil {
+        writeError(w, r, err, "Invalid request body")
         return
     }
 
     hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
     if err != nil {
-        http.Error(w, "Failed to process password", http.StatusInternalServerError)
+        writeProblem(w, r, "Failed to process password", http.StatusInternalServerError)
         return
     }
 
@@ -33,70 +49,262 @@ func (s *APIServer) handleRegister(w http.ResponseWriter, r *http.Request) {
         Password: string(hashedPassword),
     }
 
+    // An email that is already registered is a 409
     if err := s.db.CreateUser(user); err != nil {
-        if err.Error() == "email already exists" {
-            http.Error(w, "Email already registered", http.StatusBadRequest)
+        writeError(w, r, err, "Failed to create user")
+        return
+    }
+
+    if s.isBootstrapAdmin(user.Email) {
+        if err := s.db.UpdateUserRole(user.ID, types.RoleAdmin); err != nil {
+            writeError(w, r, err, "Failed to create user")
             return
         }
-        http.Error(w, "Failed to create user", http.StatusInternalServerError)
-        return
+        user.Role = types.RoleAdmin
     }
 
-    token, err := s.generateJWT(user)
+    resp, err := s.issueTokens(user, "")
     if err != nil {
-        http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+        writeProblem(w, r, "Failed to generate token", http.StatusInternalServerError)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(types.AuthResponse{
-        Token: token,
-        User:  *user,
-    })
+    json.NewEncoder(w).Encode(resp)
 }
 
 func (s *APIServer) handleLogin(w http.ResponseWriter, r *http.Request) {
     var req types.LoginRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+    if err := decodeJSON(w, r, &req); err != nil {
+        writeError(w, r, err, "Invalid request body")
         return
     }
 
-    if req.Email == "" || req.Password == "" {
-        http.Error(w, "Email and password are required", http.StatusBadRequest)
+    req.Email = strings.TrimSpace(req.Email)
+    if err := validate.Struct(&req); err != nil {
+        writeError(w, r, err, "Invalid request body")
         return
     }
 
+    // Unknown emails and wrong passwords look the same to the client, but
+    // a failing database isn't reported as bad credentials
     user, err := s.db.GetUserByEmail(req.Email)
+    if errors.Is(err, apperr.ErrNotFound) {
+        writeProblem(w, r, "Invalid credentials", http.StatusUnauthorized)
+        return
+    }
     if err != nil {
-        http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+        writeError(w, r, err, "Failed to log in")
         return
     }
 
     if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-        http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+        writeProblem(w, r, "Invalid credentials", http.StatusUnauthorized)
         return
     }
 
-    token, err := s.generateJWT(&user)
+    resp, err := s.issueTokens(&user, "")
     if err != nil {
-        http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+        writeProblem(w, r, "Failed to generate token", http.StatusInternalServerError)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(types.AuthResponse{
-        Token: token,
-        User:  user,
-    })
+    json.NewEncoder(w).Encode(resp)
+}
+
+// handleRefresh exchanges a refresh token for a new access/refresh token pair.
+// Every refresh token can be used once; presenting one that was already
+// rotated means it leaked, so the whole family is revoked.
+func (s *APIServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
+    var req types.RefreshRequest
+    if err := decodeJSON(w, r, &req); err != nil {
+        writeError(w, r, err, "Invalid request body")
+        return
+    }
+    if err := validate.Struct(&req); err != nil {
+        writeError(w, r, err, "Invalid request body")
+        return
+    }
+
+    current, err := s.db.GetRefreshTokenByHash(hashRefreshToken(req.RefreshToken))
+    if err != nil {
+        if errors.Is(err, apperr.ErrNotFound) {
+            writeProblem(w, r, "Invalid refresh token", http.StatusUnauthorized)
+            return
+        }
+        writeError(w, r, err, "Failed to refresh token")
+        return
+    }
+
+    if current.RevokedAt != nil {
+        if err := s.db.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+            writeError(w, r, err, "Failed to refresh token")
+            return
+        }
+        writeProblem(w, r, "Refresh token reuse detected, please log in again", http.StatusUnauthorized)
+        return
+    }
+
+    if time.Now().After(current.ExpiresAt) {
+        writeProblem(w, r, "Refresh token expired", http.StatusUnauthorized)
+        return
+    }
+
+    // Reload the user so that role changes are picked up on refresh
+    user, err := s.db.GetUserByID(current.UserID)
+    if errors.Is(err, apperr.ErrNotFound) {
+        writeProblem(w, r, "Invalid refresh token", http.StatusUnauthorized)
+        return
+    }
+    if err != nil {
+        writeError(w, r, err, "Failed to refresh token")
+        return
+    }
+
+    resp, err := s.rotateTokens(&user, current)
+    if err != nil {
+        if errors.Is(err, apperr.ErrConflict) {
+            if err := s.db.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+                writeError(w, r, err, "Failed to refresh token")
+                return
+            }
+            writeProblem(w, r, "Refresh token reuse detected, please log in again", http.StatusUnauthorized)
+            return
+        }
+        writeError(w, r, err, "Failed to refresh token")
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(resp)
+}
+
+// handleLogout revokes the session (token family) the given refresh token
+// belongs to. It always succeeds so that it can be retried safely.
+func (s *APIServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+    var req types.RefreshRequest
+    if err := decodeJSON(w, r, &req); err != nil {
+        writeError(w, r, err, "Invalid request body")
+        return
+    }
+    if err := validate.Struct(&req); err != nil {
+        writeError(w, r, err, "Invalid request body")
+        return
+    }
+
+    token, err := s.db.GetRefreshTokenByHash(hashRefreshToken(req.RefreshToken))
+    if err != nil {
+        if errors.Is(err, apperr.ErrNotFound) {
+            w.WriteHeader(http.StatusNoContent)
+            return
+        }
+        writeError(w, r, err, "Failed to log out")
+        return
+    }
+
+    if err := s.db.RevokeRefreshTokenFamily(token.FamilyID); err != nil {
+        writeError(w, r, err, "Failed to log out")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// handleLogoutAll revokes every refresh token of the caller. Access tokens
+// already handed out stay valid until they expire.
+func (s *APIServer) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+    user, _ := middleware.UserFromContext(r.Context())
+
+    if err := s.db.RevokeUserRefreshTokens(user.ID); err != nil {
+        writeError(w, r, err, "Failed to log out")
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// issueTokens creates an access token and a new refresh token. An empty
+// familyID starts a new session.
+func (s *APIServer) issueTokens(user *types.User, familyID string) (types.AuthResponse, error) {
+    if familyID == "" {
+        familyID = uuid.NewString()
+    }
+
+    refreshToken, record, err := newRefreshToken(user.ID, familyID)
+    if err != nil {
+        return types.AuthResponse{}, err
+    }
+
+    if err := s.db.CreateRefreshToken(record); err != nil {
+        return types.AuthResponse{}, err
+    }
+
+    return s.authResponse(user, refreshToken)
+}
+
+// rotateTokens replaces current with a new refresh token of the same family
+func (s *APIServer) rotateTokens(user *types.User, current types.RefreshToken) (types.AuthResponse, error) {
+    refreshToken, record, err := newRefreshToken(user.ID, current.FamilyID)
+    if err != nil {
+        return types.AuthResponse{}, err
+    }
+
+    if err := s.db.RotateRefreshToken(current.ID, record); err != nil {
+        return types.AuthResponse{}, err
+    }
+
+    return s.authResponse(user, refreshToken)
+}
+
+func (s *APIServer) authResponse(user *types.User, refreshToken string) (types.AuthResponse, error) {
+    token, err := s.generateJWT(user)
+    if err != nil {
+        return types.AuthResponse{}, err
+    }
+
+    return types.AuthResponse{
+        Token:        token,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int64(accessTokenTTL.Seconds()),
+        User:         *user,
+    }, nil
+}
+
+// newRefreshToken returns the opaque token handed to the client and the
+// record to persist, which only holds its hash.
+func newRefreshToken(userID, familyID string) (string, *types.RefreshToken, error) {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+        return "", nil, err
+    }
+    token := base64.RawURLEncoding.EncodeToString(buf)
+
+    return token, &types.RefreshToken{
+        ID:        uuid.NewString(),
+        UserID:    userID,
+        FamilyID:  familyID,
+        TokenHash: hashRefreshToken(token),
+        ExpiresAt: time.Now().Add(refreshTokenTTL),
+    }, nil
+}
+
+func hashRefreshToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
 }
 
 func (s *APIServer) generateJWT(user *types.User) (string, error) {
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id": user.ID,
-        "email":   user.Email,
-        "role":    user.Role,
-        "exp":     time.Now().Add(time.Hour * 24).Unix(),
+    now := time.Now()
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, middleware.Claims{
+        UserID: user.ID,
+        Email:  user.Email,
+        Role:   user.Role,
+        RegisteredClaims: jwt.RegisteredClaims{
+            Subject:   user.ID,
+            IssuedAt:  jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenTTL)),
+        },
     })
 
     return token.SignedString(s.jwtSecret)
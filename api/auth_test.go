@@ -0,0 +1,165 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/database/memory"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// brokenUsersDB fails user lookups the way an unreachable database would
+type brokenUsersDB struct {
+	*memory.MemoryDB
+}
+
+func (db brokenUsersDB) GetUserByEmail(email string) (types.User, error) {
+	return types.User{}, errors.New("connection refused")
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	a := newTestAPI(t)
+	a.register("dup@example.com")
+
+	rec := a.do("POST", "/api/auth/register", "", types.RegisterRequest{Email: "dup@example.com", Password: "password123"})
+	expectStatus(t, rec, http.StatusConflict)
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	a := newTestAPI(t)
+	a.register("user@example.com")
+
+	tests := []struct {
+		name string
+		req  types.LoginRequest
+	}{
+		{"unknown email", types.LoginRequest{Email: "nobody@example.com", Password: "password123"}},
+		{"wrong password", types.LoginRequest{Email: "user@example.com", Password: "wrong-password"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := a.do("POST", "/api/auth/login", "", tt.req)
+			expectStatus(t, rec, http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestLoginDatabaseFailure(t *testing.T) {
+	a := newTestAPI(t)
+	a.handler = api.NewAPIServer(brokenUsersDB{a.db}, testSecret).Handler()
+
+	rec := a.do("POST", "/api/auth/login", "", types.LoginRequest{Email: "user@example.com", Password: "password123"})
+	expectStatus(t, rec, http.StatusInternalServerError)
+}
+
+// refresh exchanges a refresh token and returns the response
+func (a *testAPI) refresh(token string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	return a.do("POST", "/api/auth/refresh", "", types.RefreshRequest{RefreshToken: token})
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	a := newTestAPI(t)
+	rec := a.do("POST", "/api/auth/register", "", types.RegisterRequest{Email: "user@example.com", Password: "password123"})
+	expectStatus(t, rec, http.StatusOK)
+	var first types.AuthResponse
+	decode(t, rec, &first)
+
+	rec = a.refresh(first.RefreshToken)
+	expectStatus(t, rec, http.StatusOK)
+	var second types.AuthResponse
+	decode(t, rec, &second)
+	if second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatal("refresh didn't issue new tokens")
+	}
+	expectStatus(t, a.do("GET", "/api/profiles", second.Token, nil), http.StatusOK)
+
+	// Reusing the rotated token revokes the whole session
+	expectStatus(t, a.refresh(first.RefreshToken), http.StatusUnauthorized)
+	expectStatus(t, a.refresh(second.RefreshToken), http.StatusUnauthorized)
+}
+
+func TestLogout(t *testing.T) {
+	a := newTestAPI(t)
+	login := func() types.AuthResponse {
+		rec := a.do("POST", "/api/auth/login", "", types.LoginRequest{Email: "user@example.com", Password: "password123"})
+		expectStatus(t, rec, http.StatusOK)
+		var resp types.AuthResponse
+		decode(t, rec, &resp)
+		return resp
+	}
+	a.register("user@example.com")
+
+	session, other := login(), login()
+	expectStatus(t, a.do("POST", "/api/auth/logout", "", types.RefreshRequest{RefreshToken: session.RefreshToken}), http.StatusNoContent)
+	expectStatus(t, a.refresh(session.RefreshToken), http.StatusUnauthorized)
+	// Logging out again is fine, and other sessions are kept
+	expectStatus(t, a.do("POST", "/api/auth/logout", "", types.RefreshRequest{RefreshToken: session.RefreshToken}), http.StatusNoContent)
+	rec := a.refresh(other.RefreshToken)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &other)
+
+	expectStatus(t, a.do("POST", "/api/auth/logout-all", other.Token, nil), http.StatusNoContent)
+	expectStatus(t, a.refresh(other.RefreshToken), http.StatusUnauthorized)
+}
+
+func TestAuthenticationRequired(t *testing.T) {
+	a := newTestAPI(t)
+	token, _ := a.register("user@example.com")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"no token", ""},
+		{"malformed token", "not-a-jwt"},
+		{"tampered token", token + "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, a.do("GET", "/api/profiles", tt.token, nil), http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	a := newTestAPI(t)
+	tests := []types.RegisterRequest{
+		{Email: "not-an-email", Password: "password123"},
+		{Email: "user@example.com", Password: "short"},
+		{Email: "user@example.com", Password: "onlyletters"},
+	}
+	for _, req := range tests {
+		expectStatus(t, a.do("POST", "/api/auth/register", "", req), http.StatusBadRequest)
+	}
+}
+
+// racingRefreshDB loses every rotation to a concurrent refresh and can't
+// revoke the session afterwards
+type racingRefreshDB struct {
+	*memory.MemoryDB
+}
+
+func (db racingRefreshDB) RotateRefreshToken(oldID string, next *types.RefreshToken) error {
+	return apperr.Conflict("refresh token already used")
+}
+
+func (db racingRefreshDB) RevokeRefreshTokenFamily(familyID string) error {
+	return errors.New("connection refused")
+}
+
+func TestRefreshReuseRevokeFailure(t *testing.T) {
+	a := newTestAPI(t)
+	rec := a.do("POST", "/api/auth/register", "", types.RegisterRequest{Email: "user@example.com", Password: "password123"})
+	expectStatus(t, rec, http.StatusOK)
+	var resp types.AuthResponse
+	decode(t, rec, &resp)
+
+	// The client mustn't be told the session is gone when it isn't
+	a.handler = api.NewAPIServer(racingRefreshDB{a.db}, testSecret).Handler()
+	expectStatus(t, a.refresh(resp.RefreshToken), http.StatusInternalServerError)
+}
@@ -0,0 +1,44 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	a := newTestAPI(t)
+
+	for _, path := range []string{"/api/profiles", "/api/profiles/123", "/api/teams"} {
+		rec := a.do("OPTIONS", path, "", nil,
+			"Origin", "https://app.example.com",
+			"Access-Control-Request-Method", "PATCH",
+			"Access-Control-Request-Headers", "authorization, content-type",
+		)
+		expectStatus(t, rec, http.StatusOK)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q", path, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PATCH") {
+			t.Errorf("%s: Access-Control-Allow-Methods = %q", path, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, "Authorization") {
+			t.Errorf("%s: Access-Control-Allow-Headers = %q", path, got)
+		}
+	}
+}
+
+func TestCORSHeadersOnErrors(t *testing.T) {
+	a := newTestAPI(t)
+
+	// Browsers only show the response of a failed request with CORS headers
+	for _, rec := range []interface{ Header() http.Header }{
+		a.do("GET", "/api/profiles", "", nil),
+		a.do("GET", "/nope", "", nil),
+	} {
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// maxBodyBytes bounds the size of JSON request bodies. The largest payloads
+// are profiles with their skill lists, which stay far below this.
+const maxBodyBytes = 64 << 10
+
+// decodeJSON reads a single JSON object from the request body into v.
+// Unknown fields, trailing data and bodies over maxBodyBytes are rejected.
+// The error says what is wrong and can be passed to writeError.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return apperr.InvalidInput("request body must contain a single JSON object")
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return apperr.TooLarge("request body can't be larger than %d KB", maxBytesErr.Limit>>10)
+	case errors.Is(err, io.EOF):
+		return apperr.InvalidInput("request body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperr.InvalidInput("request body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return apperr.Invalid(typeErr.Field, "%s must be a %s", typeErr.Field, typeErr.Type.String())
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperr.Invalid(field, "%s is not a known field", field)
+	default:
+		return apperr.InvalidInput("invalid request body: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+}
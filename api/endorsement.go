@@ -0,0 +1,93 @@
+package api
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// Proficiency levels a student can claim for a skill, lowest first
+const (
+	LevelBeginner     = "beginner"
+	LevelIntermediate = "intermediate"
+	LevelAdvanced     = "advanced"
+	LevelExpert       = "expert"
+)
+
+// ProficiencyLevels is ordered from lowest to highest, which is what minimum
+// proficiency filters compare against
+var ProficiencyLevels = []string{LevelBeginner, LevelIntermediate, LevelAdvanced, LevelExpert}
+
+const maxYearsOfExperience = 50
+
+// SkillLevel is the self-assessment of one of the skills of a profile
+type SkillLevel struct {
+	Skill string `json:"skill"`
+	Level string `json:"level"`
+	Years int    `json:"years"`
+}
+
+// EndorsementSummary is returned after endorsing or revoking
+type EndorsementSummary struct {
+	ProfileID    string `json:"profile_id"`
+	Skill        string `json:"skill"`
+	Endorsements int    `json:"endorsements"`
+}
+
+// ProficiencyRank returns the position of level in ProficiencyLevels
+// starting at 1, or 0 for unknown levels
+func ProficiencyRank(level string) int {
+	return slices.Index(ProficiencyLevels, level) + 1
+}
+
+// validateSkillLevels checks that every level refers to one of the skills of
+// the profile, at most once, and adopts the spelling used in Skills
+func (p *StudentProfile) validateSkillLevels() error {
+	errs := &apperr.ValidationError{}
+	seen := map[string]bool{}
+
+	for i := range p.SkillLevels {
+		level := &p.SkillLevels[i]
+		key := strings.ToLower(strings.TrimSpace(level.Skill))
+
+		index := slices.IndexFunc(p.Skills, func(skill string) bool {
+			return strings.ToLower(skill) == key
+		})
+		switch {
+		case index < 0:
+			errs.Add("skill_levels", "skill_levels has %q, which isn't one of the skills", level.Skill)
+			continue
+		case seen[key]:
+			errs.Add("skill_levels", "skill_levels has %q more than once", level.Skill)
+			continue
+		}
+		seen[key] = true
+		level.Skill = p.Skills[index]
+
+		if ProficiencyRank(level.Level) == 0 {
+			errs.Add("skill_levels", "level of %s must be one of %s", level.Skill, strings.Join(ProficiencyLevels, ", "))
+		}
+		if level.Years < 0 || level.Years > maxYearsOfExperience {
+			errs.Add("skill_levels", "years of %s must be between 0 and %d", level.Skill, maxYearsOfExperience)
+		}
+	}
+
+	return errs.Err()
+}
+
+// CanonicalLevels rewrites the skills of levels to their canonical names.
+// When two levels end up with the same skill the first one wins.
+func (l TermLookup) CanonicalLevels(levels []SkillLevel) []SkillLevel {
+	canonical := make([]SkillLevel, 0, len(levels))
+	seen := map[string]bool{}
+	for _, level := range levels {
+		level.Skill = l.Name(level.Skill)
+		if seen[strings.ToLower(level.Skill)] {
+			continue
+		}
+		seen[strings.ToLower(level.Skill)] = true
+		canonical = append(canonical, level)
+	}
+	return canonical
+}
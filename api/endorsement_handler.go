@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+)
+
+// handleEndorseSkill vouches for a skill on someone else's profile. Each
+// user endorses a skill at most once, repeating the request changes nothing.
+func (s *APIServer) handleEndorseSkill(w http.ResponseWriter, r *http.Request) {
+	profile, skill, ok := s.endorsementTarget(w, r, true)
+	if !ok {
+		return
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	if profile.UserID == user.ID {
+		writeProblem(w, r, "You can't endorse your own skills", http.StatusForbidden)
+		return
+	}
+
+	count, err := s.db.EndorseSkill(profile.ID, skill, user.ID)
+	if err != nil {
+		writeError(w, r, err, "Failed to endorse skill")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EndorsementSummary{ProfileID: profile.ID, Skill: skill, Endorsements: count})
+}
+
+func (s *APIServer) handleRevokeEndorsement(w http.ResponseWriter, r *http.Request) {
+	// Endorsements of a skill the owner removed since can still be revoked
+	profile, skill, ok := s.endorsementTarget(w, r, false)
+	if !ok {
+		return
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	count, err := s.db.RevokeEndorsement(profile.ID, skill, user.ID)
+	if err != nil {
+		writeError(w, r, err, "Failed to revoke endorsement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EndorsementSummary{ProfileID: profile.ID, Skill: skill, Endorsements: count})
+}
+
+// endorsementTarget loads the profile and resolves the skill of the path to
+// the spelling used on the profile. Unless the profile has to list the
+// skill, one it doesn't list resolves to its taxonomy name. It writes the
+// error response itself and reports whether the handler may continue.
+func (s *APIServer) endorsementTarget(w http.ResponseWriter, r *http.Request, listed bool) (StudentProfile, string, bool) {
+	vars := mux.Vars(r)
+
+	profile, err := s.db.GetProfile(vars["id"])
+	if err != nil {
+		writeError(w, r, err, "Failed to get profile")
+		return StudentProfile{}, "", false
+	}
+
+	lookup, err := s.db.TermLookup(TermSkill)
+	if err != nil {
+		writeError(w, r, err, "Failed to get profile")
+		return StudentProfile{}, "", false
+	}
+
+	skill := lookup.Name(vars["skill"])
+	for _, name := range profile.Skills {
+		if strings.EqualFold(name, skill) {
+			return profile, name, true
+		}
+	}
+	if !listed {
+		return profile, skill, true
+	}
+
+	writeProblem(w, r, "The profile doesn't list the skill "+skill, http.StatusNotFound)
+	return StudentProfile{}, "", false
+}
@@ -0,0 +1,35 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+func TestRevokeEndorsementOfRemovedSkill(t *testing.T) {
+	a := newTestAPI(t)
+	ownerToken, _ := a.register("owner@example.com")
+	profile := a.createProfile(ownerToken, testProfile("owner@example.com"))
+	endorserToken, _ := a.register("endorser@example.com")
+
+	path := "/api/profiles/" + profile.ID + "/endorsements/SQL"
+	expectStatus(t, a.do("PUT", path, endorserToken, nil), http.StatusOK)
+
+	rec := a.do("PATCH", "/api/profiles/"+profile.ID, ownerToken, `{"skills": ["Go"]}`,
+		"Content-Type", "application/merge-patch+json")
+	expectStatus(t, rec, http.StatusOK)
+
+	// Endorsing needs the skill to be listed, revoking doesn't
+	expectStatus(t, a.do("PUT", path, endorserToken, nil), http.StatusNotFound)
+
+	rec = a.do("DELETE", path, endorserToken, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var summary api.EndorsementSummary
+	decode(t, rec, &summary)
+	if summary.Endorsements != 0 {
+		t.Errorf("endorsements = %d, want 0", summary.Endorsements)
+	}
+
+	expectStatus(t, a.do("DELETE", path, endorserToken, nil), http.StatusNotFound)
+}
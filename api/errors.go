@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+)
+
+var logger = logging.New("api")
+
+// statusFromError maps the kind of a domain error to an HTTP status code.
+// Anything that isn't a domain error is a server error.
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, apperr.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperr.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperr.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, apperr.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperr.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, apperr.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeProblem is the one place handlers report failures from. Like
+// http.Error it takes the message before the status; detail is shown to the
+// client as is.
+func writeProblem(w http.ResponseWriter, r *http.Request, detail string, status int) {
+	problem.Error(w, r, detail, status)
+}
+
+// writeError responds with the status matching err. Domain errors are shown
+// to the client, with one entry per field for validation errors. Other
+// errors are logged and replaced by fallback so that database details don't
+// leak.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	status := statusFromError(err)
+	if status == http.StatusInternalServerError {
+		logger.ErrorContext(r.Context(), fallback, "error", err)
+		writeProblem(w, r, fallback, status)
+		return
+	}
+
+	p := problem.New(status, capitalize(err.Error()))
+
+	// Validation messages start with the field name, which keeps its case
+	var validation *apperr.ValidationError
+	if errors.As(err, &validation) {
+		p.Detail = err.Error()
+		for _, field := range validation.Fields {
+			p.Errors = append(p.Errors, problem.FieldError{
+				Field:   field.Field,
+				Message: field.Message,
+			})
+		}
+	}
+
+	problem.Write(w, r, p)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
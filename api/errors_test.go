@@ -0,0 +1,46 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func TestDatabaseErrorsAreLoggedWithRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	if err := logging.Configure(&logs, "json", logging.Levels{Default: slog.LevelInfo}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logging.Configure(os.Stderr, "text", logging.Levels{Default: slog.LevelInfo}) })
+
+	a := newTestAPI(t)
+	a.handler = api.NewAPIServer(brokenUsersDB{a.db}, testSecret).Handler()
+
+	rec := a.do("POST", "/api/auth/login", "", types.LoginRequest{Email: "user@example.com", Password: "password123"},
+		"X-Request-ID", "req-123")
+	expectStatus(t, rec, http.StatusInternalServerError)
+
+	var found bool
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("decoding %s: %v", line, err)
+		}
+		if record["level"] == "ERROR" && record["package"] == "api" {
+			found = true
+			if record["request_id"] != "req-123" || record["error"] != "connection refused" {
+				t.Errorf("error logged as %s", line)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no error logged:\n%s", logs.String())
+	}
+}
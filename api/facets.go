@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+const (
+	defaultFacetLimit = 10
+	maxFacetLimit     = 50
+)
+
+// FacetCount is the number of matching profiles with one value of a field
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets break the result set of a search down by field. Skills and Focus
+// only hold the most common values, the other fields every value. Values
+// are sorted by count, semesters in ascending order.
+type Facets struct {
+	Faculty      []FacetCount `json:"faculty"`
+	FieldOfStudy []FacetCount `json:"field_of_study"`
+	Semester     []FacetCount `json:"semester"`
+	Skills       []FacetCount `json:"skills"`
+	Focus        []FacetCount `json:"focus"`
+	Availability []FacetCount `json:"availability"`
+}
+
+// ProfileSearchPage is a page of search results with the facets of the
+// whole result set when they were asked for
+type ProfileSearchPage struct {
+	ProfilePage
+	Facets *Facets `json:"facets,omitempty"`
+}
+
+// NewFacets returns facets without nil lists, so that empty facets are
+// encoded as []
+func NewFacets() Facets {
+	return Facets{
+		Faculty:      []FacetCount{},
+		FieldOfStudy: []FacetCount{},
+		Semester:     []FacetCount{},
+		Skills:       []FacetCount{},
+		Focus:        []FacetCount{},
+		Availability: []FacetCount{},
+	}
+}
+
+// parseFacetLimit reads facets and facet_limit from the query string. It
+// returns how many skills and focus areas to count, or 0 when no facets
+// were asked for.
+func parseFacetLimit(query url.Values) (int, error) {
+	v := query.Get("facets")
+	if v == "" {
+		return 0, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return 0, apperr.Invalid("facets", "facets must be true or false")
+	}
+	if !enabled {
+		return 0, nil
+	}
+
+	limit := defaultFacetLimit
+	if v := query.Get("facet_limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, apperr.Invalid("facet_limit", "facet_limit must be a positive integer")
+		}
+		limit = min(limit, maxFacetLimit)
+	}
+	return limit, nil
+}
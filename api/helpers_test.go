@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/database/memory"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+var testSecret = []byte("test-secret-test-secret-test-secret")
+
+// testAPI serves the API on an in-memory store
+type testAPI struct {
+	t       *testing.T
+	db      *memory.MemoryDB
+	handler http.Handler
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	db := memory.NewMemoryDB()
+	return &testAPI{t: t, db: db, handler: api.NewAPIServer(db, testSecret).Handler()}
+}
+
+// do sends a request with body encoded as JSON unless it is nil or already
+// a string. Headers come in name, value pairs.
+func (a *testAPI) do(method, path, token string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// register creates an account and returns its access token and user
+func (a *testAPI) register(email string) (string, types.User) {
+	a.t.Helper()
+	rec := a.do("POST", "/api/auth/register", "", types.RegisterRequest{Email: email, Password: "password123"})
+	expectStatus(a.t, rec, http.StatusOK)
+
+	var resp types.AuthResponse
+	decode(a.t, rec, &resp)
+	return resp.Token, resp.User
+}
+
+// registerAs creates an account with role. The token is issued after the
+// role change, as roles are part of the token.
+func (a *testAPI) registerAs(email, role string) (string, types.User) {
+	a.t.Helper()
+	_, user := a.register(email)
+	if err := a.db.UpdateUserRole(user.ID, role); err != nil {
+		a.t.Fatal(err)
+	}
+
+	rec := a.do("POST", "/api/auth/login", "", types.LoginRequest{Email: email, Password: "password123"})
+	expectStatus(a.t, rec, http.StatusOK)
+
+	var resp types.AuthResponse
+	decode(a.t, rec, &resp)
+	return resp.Token, resp.User
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+	}
+}
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultMatchResults = 10
+	maxMatchResults     = 50
+
+	// Semester distance at which proximity drops to zero
+	maxSemesterDistance = 8
+)
+
+// Share of each criterion in the final score. Criteria the request doesn't
+// use (no focus, no target semester) are left out and the rest rescaled.
+const (
+	skillWeight        = 0.5
+	focusWeight        = 0.25
+	semesterWeight     = 0.15
+	availabilityWeight = 0.1
+)
+
+// WeightedTerm is a skill or focus area a team needs. A higher weight makes
+// it count more; zero or missing weights count as 1.
+type WeightedTerm struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+type MatchRequest struct {
+	Skills         []WeightedTerm `json:"skills"`
+	Focus          []WeightedTerm `json:"focus"`
+	TargetSemester int            `json:"target_semester"`
+	OnlyAvailable  bool           `json:"only_available"`
+	Limit          int            `json:"limit"`
+}
+
+// ScoreBreakdown holds each criterion on a 0..1 scale before weighting
+type ScoreBreakdown struct {
+	SkillCoverage     float64 `json:"skill_coverage"`
+	FocusOverlap      float64 `json:"focus_overlap"`
+	SemesterProximity float64 `json:"semester_proximity"`
+	Availability      float64 `json:"availability"`
+}
+
+type MatchResult struct {
+	Profile       StudentProfile `json:"profile"`
+	Score         float64        `json:"score"`
+	Breakdown     ScoreBreakdown `json:"breakdown"`
+	MatchedSkills []string       `json:"matched_skills"`
+	MissingSkills []string       `json:"missing_skills"`
+	MatchedFocus  []string       `json:"matched_focus"`
+}
+
+// MatchQuery is a match request folded into the score each matching term,
+// the semester and availability add, so that a store can rank all candidates
+// without knowing the scoring weights. Names are compared case-insensitively.
+type MatchQuery struct {
+	Skills      []string
+	SkillScores []float64
+	Focus       []string
+	FocusScores []float64
+
+	// SemesterScore is added at TargetSemester and falls to zero
+	// SemesterRange semesters away
+	TargetSemester int
+	SemesterScore  float64
+	SemesterRange  int
+
+	AvailableScore float64
+	OnlyAvailable  bool
+	ExcludeUserID  string
+}
+
+// query builds the store query for the request, leaving out the profile of
+// excludeUserID
+func (req MatchRequest) query(excludeUserID string) MatchQuery {
+	q := MatchQuery{
+		Skills:         termNames(req.Skills),
+		Focus:          termNames(req.Focus),
+		TargetSemester: req.TargetSemester,
+		SemesterRange:  maxSemesterDistance,
+		OnlyAvailable:  req.OnlyAvailable,
+		ExcludeUserID:  excludeUserID,
+	}
+
+	totalWeight := availabilityWeight
+	if len(req.Skills) > 0 {
+		totalWeight += skillWeight
+	}
+	if len(req.Focus) > 0 {
+		totalWeight += focusWeight
+	}
+	if req.TargetSemester > 0 {
+		totalWeight += semesterWeight
+		q.SemesterScore = semesterWeight / totalWeight
+	}
+	q.SkillScores = termScores(req.Skills, skillWeight/totalWeight)
+	q.FocusScores = termScores(req.Focus, focusWeight/totalWeight)
+	q.AvailableScore = availabilityWeight / totalWeight
+
+	return q
+}
+
+// Score is the unrounded score of profile, the same scoreCandidate gives
+func (q MatchQuery) Score(profile StudentProfile) float64 {
+	score := matchedScore(q.Skills, q.SkillScores, profile.Skills) +
+		matchedScore(q.Focus, q.FocusScores, profile.Focus)
+
+	if q.SemesterScore > 0 {
+		distance := math.Abs(float64(profile.Semester - q.TargetSemester))
+		score += q.SemesterScore * math.Max(0, 1-distance/float64(q.SemesterRange))
+	}
+	if profile.IsAvailable {
+		score += q.AvailableScore
+	}
+	return score
+}
+
+// termScores splits share between the terms by their weight
+func termScores(terms []WeightedTerm, share float64) []float64 {
+	var total float64
+	for _, term := range terms {
+		total += termWeight(term)
+	}
+
+	scores := make([]float64, 0, len(terms))
+	for _, term := range terms {
+		scores = append(scores, share*termWeight(term)/total)
+	}
+	return scores
+}
+
+func matchedScore(names []string, scores []float64, have []string) float64 {
+	var score float64
+	for i, name := range names {
+		for _, h := range have {
+			if strings.EqualFold(h, name) {
+				score += scores[i]
+				break
+			}
+		}
+	}
+	return score
+}
+
+// rankCandidates scores every candidate against the request and returns the
+// best limit results, highest score first.
+func rankCandidates(req MatchRequest, candidates []StudentProfile, limit int) []MatchResult {
+	results := make([]MatchResult, 0, len(candidates))
+	for _, candidate := range candidates {
+		results = append(results, scoreCandidate(req, candidate))
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func scoreCandidate(req MatchRequest, profile StudentProfile) MatchResult {
+	result := MatchResult{
+		Profile:       profile,
+		MatchedSkills: []string{},
+		MissingSkills: []string{},
+		MatchedFocus:  []string{},
+	}
+
+	var score, totalWeight float64
+
+	if len(req.Skills) > 0 {
+		coverage, matched, missing := weightedCoverage(req.Skills, profile.Skills)
+		result.Breakdown.SkillCoverage = coverage
+		result.MatchedSkills = matched
+		result.MissingSkills = missing
+		score += skillWeight * coverage
+		totalWeight += skillWeight
+	}
+
+	if len(req.Focus) > 0 {
+		overlap, matched, _ := weightedCoverage(req.Focus, profile.Focus)
+		result.Breakdown.FocusOverlap = overlap
+		result.MatchedFocus = matched
+		score += focusWeight * overlap
+		totalWeight += focusWeight
+	}
+
+	if req.TargetSemester > 0 {
+		distance := math.Abs(float64(profile.Semester - req.TargetSemester))
+		proximity := math.Max(0, 1-distance/maxSemesterDistance)
+		result.Breakdown.SemesterProximity = proximity
+		score += semesterWeight * proximity
+		totalWeight += semesterWeight
+	}
+
+	if profile.IsAvailable {
+		result.Breakdown.Availability = 1
+		score += availabilityWeight
+	}
+	totalWeight += availabilityWeight
+
+	result.Score = round(score / totalWeight)
+	result.Breakdown.SkillCoverage = round(result.Breakdown.SkillCoverage)
+	result.Breakdown.FocusOverlap = round(result.Breakdown.FocusOverlap)
+	result.Breakdown.SemesterProximity = round(result.Breakdown.SemesterProximity)
+
+	return result
+}
+
+// weightedCoverage returns the share of the wanted weight that have is
+// covering, comparing names case-insensitively
+func weightedCoverage(wanted []WeightedTerm, have []string) (float64, []string, []string) {
+	owned := make(map[string]bool, len(have))
+	for _, h := range have {
+		owned[strings.ToLower(h)] = true
+	}
+
+	matched, missing := []string{}, []string{}
+	var covered, total float64
+
+	for _, term := range wanted {
+		weight := termWeight(term)
+		total += weight
+
+		if owned[strings.ToLower(term.Name)] {
+			covered += weight
+			matched = append(matched, term.Name)
+		} else {
+			missing = append(missing, term.Name)
+		}
+	}
+
+	if total == 0 {
+		return 0, matched, missing
+	}
+	return covered / total, matched, missing
+}
+
+// CanonicalWeighted rewrites weighted terms to their canonical names. When
+// two terms end up with the same name the first one wins.
+func (l TermLookup) CanonicalWeighted(terms []WeightedTerm) []WeightedTerm {
+	canonical := make([]WeightedTerm, 0, len(terms))
+	seen := map[string]bool{}
+	for _, term := range terms {
+		term.Name = l.Name(term.Name)
+		if seen[strings.ToLower(term.Name)] {
+			continue
+		}
+		seen[strings.ToLower(term.Name)] = true
+		canonical = append(canonical, term)
+	}
+	return canonical
+}
+
+// termWeight counts zero or missing weights as 1
+func termWeight(term WeightedTerm) float64 {
+	if term.Weight <= 0 {
+		return 1
+	}
+	return term.Weight
+}
+
+func termNames(terms []WeightedTerm) []string {
+	names := make([]string, 0, len(terms))
+	for _, t := range terms {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+func TestMatchRanksEveryCandidate(t *testing.T) {
+	a := newTestAPI(t)
+	token, user := a.register("requester@example.com")
+
+	// Plenty of weakly matching profiles must not crowd out the few that
+	// have the heavily weighted skill
+	add := func(email string, skills ...string) string {
+		profile := testProfile(email)
+		profile.Skills = skills
+		if err := a.db.CreateProfile(&profile); err != nil {
+			t.Fatal(err)
+		}
+		return profile.ID
+	}
+	for i := 0; i < 600; i++ {
+		add(fmt.Sprintf("polyglot%d@example.com", i), "Python", "Java")
+	}
+	strong := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		strong[add(fmt.Sprintf("gopher%d@example.com", i), "Go")] = true
+	}
+	own := testProfile("requester@example.com")
+	own.UserID = user.ID
+	own.Skills = []string{"Go", "Python", "Java"}
+	if err := a.db.CreateProfile(&own); err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.MatchRequest{
+		Skills: []api.WeightedTerm{{Name: "Go", Weight: 10}, {Name: "Python", Weight: 1}, {Name: "Java", Weight: 1}},
+		Limit:  4,
+	}
+	rec := a.do("POST", "/api/profiles/match", token, req)
+	expectStatus(t, rec, http.StatusOK)
+	var results []api.MatchResult
+	decode(t, rec, &results)
+
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for i, result := range results[:3] {
+		if !strong[result.Profile.ID] {
+			t.Errorf("result %d is %v, want a Go profile", i, result.Profile.Skills)
+		}
+	}
+	for _, result := range results {
+		if result.Profile.ID == own.ID {
+			t.Error("the requester was matched with their own profile")
+		}
+	}
+}
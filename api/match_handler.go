@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+)
+
+// handleMatchProfiles ranks students against the skills and focus areas a
+// team needs and explains each score
+func (s *APIServer) handleMatchProfiles(w http.ResponseWriter, r *http.Request) {
+	var req MatchRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if len(req.Skills) == 0 && len(req.Focus) == 0 {
+		writeProblem(w, r, "At least one skill or focus area is required", http.StatusBadRequest)
+		return
+	}
+
+	for _, term := range append(req.Skills, req.Focus...) {
+		if strings.TrimSpace(term.Name) == "" || term.Weight < 0 {
+			writeProblem(w, r, "Skills and focus areas need a name and a non-negative weight", http.StatusBadRequest)
+			return
+		}
+	}
+
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to match profiles")
+		return
+	}
+	terms.canonicalizeMatch(&req)
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultMatchResults
+	}
+	limit = min(limit, maxMatchResults)
+
+	// Nobody needs to be recommended to themselves
+	user, _ := middleware.UserFromContext(r.Context())
+	candidates, err := s.db.FindMatchCandidates(req.query(user.ID), limit)
+	if err != nil {
+		writeError(w, r, err, "Failed to match profiles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rankCandidates(req, candidates, limit))
+}
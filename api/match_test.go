@@ -0,0 +1,146 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWeightedCoverage(t *testing.T) {
+	wanted := []WeightedTerm{{Name: "Go", Weight: 3}, {Name: "Docker"}, {Name: "SQL", Weight: -1}}
+
+	coverage, matched, missing := weightedCoverage(wanted, []string{"go", "SQL", "React"})
+	// Go (3) and SQL (missing weights count as 1) of 5
+	if coverage != 0.8 {
+		t.Errorf("coverage = %v, want 0.8", coverage)
+	}
+	if !reflect.DeepEqual(matched, []string{"Go", "SQL"}) || !reflect.DeepEqual(missing, []string{"Docker"}) {
+		t.Errorf("matched %v, missing %v", matched, missing)
+	}
+
+	coverage, matched, missing = weightedCoverage(nil, []string{"Go"})
+	if coverage != 0 || len(matched) != 0 || len(missing) != 0 {
+		t.Errorf("nothing wanted: coverage %v, matched %v, missing %v", coverage, matched, missing)
+	}
+}
+
+func TestScoreCandidate(t *testing.T) {
+	profile := StudentProfile{
+		Skills:      []string{"Go", "Docker"},
+		Focus:       []string{"Backend"},
+		Semester:    5,
+		IsAvailable: true,
+	}
+
+	tests := []struct {
+		name      string
+		req       MatchRequest
+		profile   func(*StudentProfile)
+		score     float64
+		breakdown ScoreBreakdown
+	}{
+		{
+			name:      "perfect match",
+			req:       MatchRequest{Skills: []WeightedTerm{{Name: "Go"}}, Focus: []WeightedTerm{{Name: "Backend"}}, TargetSemester: 5},
+			score:     1,
+			breakdown: ScoreBreakdown{SkillCoverage: 1, FocusOverlap: 1, SemesterProximity: 1, Availability: 1},
+		},
+		{
+			// Skills 0.5 of 0.5 and availability 0.1, rescaled without focus and semester
+			name:      "skills only",
+			req:       MatchRequest{Skills: []WeightedTerm{{Name: "Go"}, {Name: "Rust"}}},
+			score:     round((0.5*0.5 + 0.1) / 0.6),
+			breakdown: ScoreBreakdown{SkillCoverage: 0.5, Availability: 1},
+		},
+		{
+			name:      "unavailable",
+			req:       MatchRequest{Skills: []WeightedTerm{{Name: "Go"}}},
+			profile:   func(p *StudentProfile) { p.IsAvailable = false },
+			score:     round(0.5 / 0.6),
+			breakdown: ScoreBreakdown{SkillCoverage: 1},
+		},
+		{
+			name:      "semester distance",
+			req:       MatchRequest{Focus: []WeightedTerm{{Name: "Backend"}}, TargetSemester: 1},
+			score:     round((0.25 + 0.15*0.5 + 0.1) / 0.5),
+			breakdown: ScoreBreakdown{FocusOverlap: 1, SemesterProximity: 0.5, Availability: 1},
+		},
+		{
+			name:      "semester too far",
+			req:       MatchRequest{Skills: []WeightedTerm{{Name: "Rust"}}, TargetSemester: 14},
+			profile:   func(p *StudentProfile) { p.Semester = 1 },
+			score:     round(0.1 / 0.75),
+			breakdown: ScoreBreakdown{Availability: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := profile
+			if tt.profile != nil {
+				tt.profile(&p)
+			}
+			result := scoreCandidate(tt.req, p)
+			if math.Abs(result.Score-tt.score) > 1e-9 {
+				t.Errorf("score = %v, want %v", result.Score, tt.score)
+			}
+			if result.Breakdown != tt.breakdown {
+				t.Errorf("breakdown = %+v, want %+v", result.Breakdown, tt.breakdown)
+			}
+		})
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	req := MatchRequest{Skills: []WeightedTerm{{Name: "Go", Weight: 2}, {Name: "SQL"}}}
+	candidates := []StudentProfile{
+		{ID: "sql", Skills: []string{"SQL"}},
+		{ID: "both", Skills: []string{"Go", "SQL"}},
+		{ID: "go", Skills: []string{"Go"}},
+		{ID: "go-too", Skills: []string{"go"}},
+	}
+
+	results := rankCandidates(req, candidates, 3)
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.Profile.ID)
+	}
+	// Ties keep the order of the candidates
+	if !reflect.DeepEqual(ids, []string{"both", "go", "go-too"}) {
+		t.Errorf("ranked %v", ids)
+	}
+}
+
+func TestCanonicalWeighted(t *testing.T) {
+	lookup := TermLookup{"go": "Go", "golang": "Go"}
+	got := lookup.CanonicalWeighted([]WeightedTerm{{Name: "golang", Weight: 2}, {Name: " GO "}, {Name: "Rust"}})
+	want := []WeightedTerm{{Name: "Go", Weight: 2}, {Name: "Rust"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("canonical terms = %+v, want %+v", got, want)
+	}
+}
+
+func TestMatchQueryScore(t *testing.T) {
+	requests := []MatchRequest{
+		{Skills: []WeightedTerm{{Name: "Go", Weight: 10}, {Name: "Python"}, {Name: "Java"}}},
+		{Skills: []WeightedTerm{{Name: "Go"}}, Focus: []WeightedTerm{{Name: "Backend", Weight: 2}, {Name: "Cloud"}}, TargetSemester: 3},
+		{Focus: []WeightedTerm{{Name: "Backend"}}, TargetSemester: 7},
+	}
+	profiles := []StudentProfile{
+		{Skills: []string{"go"}, Semester: 1, IsAvailable: true},
+		{Skills: []string{"Python", "Java"}, Focus: []string{"Cloud"}, Semester: 3},
+		{Skills: []string{"Go", "Java"}, Focus: []string{"Backend"}, Semester: 12, IsAvailable: true},
+		{Semester: 5},
+	}
+
+	// The store ranks by the query score, which has to agree with the score
+	// the response explains
+	for i, req := range requests {
+		q := req.query("")
+		for j, profile := range profiles {
+			want := scoreCandidate(req, profile).Score
+			if got := round(q.Score(profile)); got != want {
+				t.Errorf("request %d, profile %d: query score = %v, want %v", i, j, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Sort keys accepted by the listing endpoints
+const (
+	SortCreatedAt = "created_at"
+	SortName      = "name"
+	SortSemester  = "semester"
+	// SortRelevance orders search results by how well they match q
+	SortRelevance = "relevance"
+)
+
+// ListOptions controls pagination of profile listings. Either Offset or
+// After is used, never both. Profiles with the same sort value are ordered by
+// ID so that pages are stable.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+	After  *Cursor
+}
+
+// Cursor points at the last item of a page for keyset pagination. It carries
+// the sort it was created for so that following pages keep the same order.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Page is the envelope returned by every paginated endpoint
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+type ProfilePage = Page[StudentProfile]
+
+// NewProfilePage builds a page from up to opts.Limit+1 profiles. Database
+// implementations fetch one extra row so that we know whether there is a
+// next page without another query.
+func NewProfilePage(opts ListOptions, profiles []StudentProfile, total int) ProfilePage {
+	return newPage(opts, profiles, total, func(p StudentProfile) (string, string) {
+		return SortValue(p, opts.Sort), p.ID
+	})
+}
+
+// newPage trims items to opts.Limit and derives the next cursor from the
+// last item kept. key returns the sort value and ID of an item.
+func newPage[T any](opts ListOptions, items []T, total int, key func(T) (string, string)) Page[T] {
+	page := Page[T]{
+		Items: items,
+		Total: total,
+	}
+
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		value, id := key(page.Items[len(page.Items)-1])
+		page.NextCursor = EncodeCursor(Cursor{
+			Sort:  opts.Sort,
+			Desc:  opts.Desc,
+			Value: value,
+			ID:    id,
+		})
+	}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	return page
+}
+
+// SortValue returns the value of the sort key of a profile as stored in a cursor
+func SortValue(profile StudentProfile, sort string) string {
+	switch sort {
+	case SortName:
+		return profile.Name
+	case SortSemester:
+		return strconv.Itoa(profile.Semester)
+	case SortRelevance:
+		// Ranks are single precision in Postgres as well
+		if profile.Match == nil {
+			return "0"
+		}
+		return strconv.FormatFloat(profile.Match.Rank, 'g', -1, 32)
+	default:
+		return profile.CreatedAt
+	}
+}
+
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, apperr.Invalid("cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || !isValidSort(c.Sort) {
+		return Cursor{}, apperr.Invalid("cursor", "invalid cursor")
+	}
+
+	return c, nil
+}
+
+func isValidSort(sort string) bool {
+	return sort == SortCreatedAt || sort == SortName || sort == SortSemester || sort == SortRelevance
+}
+
+// parseListOptions reads limit, offset, cursor, sort and order from the query
+// string. Page sizes above maxPageSize are capped rather than rejected.
+func parseListOptions(query url.Values) (ListOptions, error) {
+	opts := ListOptions{
+		Limit: defaultPageSize,
+		Sort:  SortCreatedAt,
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return ListOptions{}, apperr.Invalid("limit", "limit must be a positive integer")
+		}
+		opts.Limit = min(limit, maxPageSize)
+	}
+
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return ListOptions{}, apperr.Invalid("offset", "offset must be a non-negative integer")
+		}
+		opts.Offset = offset
+	}
+
+	if v := query.Get("sort"); v != "" {
+		if !isValidSort(v) {
+			return ListOptions{}, apperr.Invalid("sort", "sort must be one of created_at, name, semester, relevance")
+		}
+		opts.Sort = v
+	}
+
+	// Newest and best matches first by default, alphabetical/ascending for
+	// the other keys
+	opts.Desc = opts.Sort == SortCreatedAt || opts.Sort == SortRelevance
+	switch query.Get("order") {
+	case "":
+	case "asc":
+		opts.Desc = false
+	case "desc":
+		opts.Desc = true
+	default:
+		return ListOptions{}, apperr.Invalid("order", "order must be asc or desc")
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		if opts.Offset > 0 {
+			return ListOptions{}, apperr.Invalid("cursor", "cursor and offset can't be combined")
+		}
+		cursor, err := DecodeCursor(v)
+		if err != nil {
+			return ListOptions{}, err
+		}
+		opts.After = &cursor
+		opts.Sort = cursor.Sort
+		opts.Desc = cursor.Desc
+	}
+
+	return opts, nil
+}
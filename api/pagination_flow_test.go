@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+func TestProfileCursorPagination(t *testing.T) {
+	a := newTestAPI(t)
+	var token string
+	created := map[string]bool{}
+	for i := 0; i < 7; i++ {
+		email := fmt.Sprintf("student%d@example.com", i)
+		token, _ = a.register(email)
+		profile := testProfile(email)
+		profile.Name = fmt.Sprintf("Student %d", i%3)
+		created[a.createProfile(token, profile).ID] = true
+	}
+
+	for _, sort := range []string{"created_at", "name", "semester"} {
+		t.Run(sort, func(t *testing.T) {
+			seen := map[string]bool{}
+			query := url.Values{"limit": {"3"}, "sort": {sort}}
+			pages := 0
+			for {
+				rec := a.do("GET", "/api/profiles?"+query.Encode(), token, nil)
+				expectStatus(t, rec, http.StatusOK)
+				var page api.ProfilePage
+				decode(t, rec, &page)
+				pages++
+
+				if page.Total != len(created) {
+					t.Errorf("total = %d, want %d", page.Total, len(created))
+				}
+				for _, p := range page.Items {
+					if seen[p.ID] {
+						t.Errorf("profile %s is on two pages", p.ID)
+					}
+					seen[p.ID] = true
+				}
+
+				if page.NextCursor == "" {
+					break
+				}
+				if pages > len(created) {
+					t.Fatal("pagination doesn't end")
+				}
+				query.Set("cursor", page.NextCursor)
+				// The cursor decides the order from now on
+				query.Set("sort", "semester")
+			}
+
+			if len(seen) != len(created) || pages != 3 {
+				t.Errorf("saw %d profiles on %d pages, want %d on 3", len(seen), pages, len(created))
+			}
+		})
+	}
+}
+
+func TestProfilePaginationInvalid(t *testing.T) {
+	a := newTestAPI(t)
+	token, _ := a.register("user@example.com")
+
+	for _, query := range []string{"cursor=garbage", "limit=0", "sort=email", "offset=2&cursor=" + api.EncodeCursor(api.Cursor{Sort: "name", ID: "x"})} {
+		expectStatus(t, a.do("GET", "/api/profiles?"+query, token, nil), http.StatusBadRequest)
+	}
+}
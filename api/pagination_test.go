@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{Sort: SortCreatedAt, Desc: true, Value: "2024-01-02T03:04:05Z", ID: "a"},
+		{Sort: SortName, Value: "Zoë, \"the\" student", ID: "b"},
+		{Sort: SortSemester, Value: "3", ID: "c"},
+		{Sort: SortRelevance, Desc: true, Value: "0.0607927", ID: "d"},
+	}
+	for _, c := range cursors {
+		encoded := EncodeCursor(c)
+		if _, err := url.ParseQuery("cursor=" + encoded); err != nil {
+			t.Errorf("cursor %q isn't safe in a query string: %v", encoded, err)
+		}
+
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q): %v", encoded, err)
+		}
+		if decoded != c {
+			t.Errorf("decoded %+v, want %+v", decoded, c)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := map[string]string{
+		"not base64":   "!!!",
+		"not JSON":     encode("cursor"),
+		"missing ID":   encode(`{"s":"name","v":"Ann"}`),
+		"unknown sort": encode(`{"s":"email","v":"x","id":"a"}`),
+		"empty":        "",
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeCursor(cursor)
+			if !errors.Is(err, apperr.ErrInvalidInput) {
+				t.Errorf("error = %v, want invalid input", err)
+			}
+		})
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Sort: SortName, Desc: true, Value: "Ann", ID: "a"})
+
+	tests := []struct {
+		query string
+		want  ListOptions
+	}{
+		{"", ListOptions{Limit: defaultPageSize, Sort: SortCreatedAt, Desc: true}},
+		{"limit=5&offset=10", ListOptions{Limit: 5, Offset: 10, Sort: SortCreatedAt, Desc: true}},
+		{"limit=1000", ListOptions{Limit: maxPageSize, Sort: SortCreatedAt, Desc: true}},
+		{"sort=name", ListOptions{Limit: defaultPageSize, Sort: SortName}},
+		{"sort=semester&order=desc", ListOptions{Limit: defaultPageSize, Sort: SortSemester, Desc: true}},
+		{"sort=relevance", ListOptions{Limit: defaultPageSize, Sort: SortRelevance, Desc: true}},
+		{"order=asc", ListOptions{Limit: defaultPageSize, Sort: SortCreatedAt}},
+		// The cursor keeps the order of the page it came from
+		{"sort=semester&order=asc&cursor=" + cursor, ListOptions{Limit: defaultPageSize, Sort: SortName, Desc: true}},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseListOptions(query)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		after := opts.After
+		opts.After = nil
+		if opts != tt.want {
+			t.Errorf("%q: options = %+v, want %+v", tt.query, opts, tt.want)
+		}
+		if (after != nil) != query.Has("cursor") {
+			t.Errorf("%q: after = %+v", tt.query, after)
+		}
+	}
+}
+
+func TestParseListOptionsInvalid(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Sort: SortName, Value: "Ann", ID: "a"})
+
+	tests := map[string]string{
+		"limit=0":                      "limit",
+		"limit=ten":                    "limit",
+		"offset=-1":                    "offset",
+		"sort=email":                   "sort",
+		"order=up":                     "order",
+		"cursor=bad":                   "cursor",
+		"offset=5&cursor=" + cursor:    "cursor",
+		"limit=5&cursor=" + cursor[1:]: "cursor",
+	}
+	for raw, field := range tests {
+		query, _ := url.ParseQuery(raw)
+		_, err := parseListOptions(query)
+
+		var validation *apperr.ValidationError
+		if !errors.As(err, &validation) || validation.Fields[0].Field != field {
+			t.Errorf("%q: error = %v, want one for %s", raw, err, field)
+		}
+	}
+}
+
+func TestNewProfilePage(t *testing.T) {
+	profiles := []StudentProfile{
+		{ID: "a", Name: "Ann"},
+		{ID: "b", Name: "Bob"},
+		{ID: "c", Name: "Cid"},
+	}
+	opts := ListOptions{Limit: 2, Sort: SortName}
+
+	page := NewProfilePage(opts, profiles, 7)
+	if len(page.Items) != 2 || page.Total != 7 {
+		t.Fatalf("page has %d items of %d, want 2 of 7", len(page.Items), page.Total)
+	}
+	next, err := DecodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != (Cursor{Sort: SortName, Value: "Bob", ID: "b"}) {
+		t.Errorf("next cursor = %+v, want the last item of the page", next)
+	}
+
+	last := NewProfilePage(opts, profiles[:2], 2)
+	if last.NextCursor != "" {
+		t.Errorf("last page has a next cursor")
+	}
+
+	empty := NewProfilePage(opts, nil, 0)
+	if empty.Items == nil {
+		t.Errorf("empty page has nil items, which encode as null")
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+func testPatchProfile() StudentProfile {
+	return StudentProfile{
+		ID:           "profile-id",
+		UserID:       "user-id",
+		Name:         "Ann",
+		Email:        "ann@example.com",
+		Faculty:      "Engineering",
+		FieldOfStudy: "Computer Science",
+		Semester:     3,
+		Skills:       []string{"Go", "SQL"},
+		Focus:        []string{"Backend"},
+		Bio:          "Hi",
+		SkillLevels:  []SkillLevel{{Skill: "Go", Level: "advanced", Years: 2}},
+		IsAvailable:  true,
+		CreatedAt:    "2024-01-01T00:00:00Z",
+		UpdatedAt:    "2024-01-02T00:00:00Z",
+		Version:      4,
+		Endorsements: map[string]int{"Go": 2},
+	}
+}
+
+func decodePatch(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var p map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		edit  func(*StudentProfile)
+	}{
+		{"empty patch", `{}`, func(p *StudentProfile) {}},
+		{"scalar", `{"is_available": false, "semester": 4}`, func(p *StudentProfile) {
+			p.IsAvailable = false
+			p.Semester = 4
+		}},
+		{"arrays are replaced", `{"skills": ["Rust"]}`, func(p *StudentProfile) { p.Skills = []string{"Rust"} }},
+		{"null removes", `{"bio": null, "focus": null}`, func(p *StudentProfile) {
+			p.Bio = ""
+			p.Focus = nil
+		}},
+		{"read-only fields are kept", `{"id": "other", "user_id": "other", "version": 9, "created_at": "x", "endorsements": {"Go": 100}}`, func(p *StudentProfile) {}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := testPatchProfile()
+			got, err := applyMergePatch(profile, decodePatch(t, tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := testPatchProfile()
+			tt.edit(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("patched\n%+v\nwant\n%+v", got, want)
+			}
+			if !reflect.DeepEqual(profile, testPatchProfile()) {
+				t.Errorf("the original profile was changed")
+			}
+		})
+	}
+}
+
+func TestApplyMergePatchInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field": `{"nickname": "Annie"}`,
+		"wrong type":    `{"semester": "three"}`,
+		"wrong nested":  `{"skill_levels": [{"skill": 1}]}`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := applyMergePatch(testPatchProfile(), decodePatch(t, raw))
+			if !errors.Is(err, apperr.ErrInvalidInput) {
+				t.Errorf("error = %v, want invalid input", err)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples of RFC 7386, appendix A
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		doc := decodePatch(t, tt.doc)
+		mergePatch(doc, decodePatch(t, tt.patch))
+		if want := decodePatch(t, tt.want); !reflect.DeepEqual(doc, want) {
+			t.Errorf("%s patched with %s = %v, want %s", tt.doc, tt.patch, doc, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+)
+
+// normalize trims the text fields and removes blank and duplicate skills and
+// focus areas, keeping the first spelling of each
+func (p *StudentProfile) normalize() {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Email = strings.TrimSpace(p.Email)
+	p.Faculty = strings.TrimSpace(p.Faculty)
+	p.FieldOfStudy = strings.TrimSpace(p.FieldOfStudy)
+	p.Bio = strings.TrimSpace(p.Bio)
+	p.Skills = dedupe(p.Skills)
+	p.Focus = dedupe(p.Focus)
+}
+
+// validate normalizes the profile and reports every rule it breaks, see the
+// validate tags of StudentProfile
+func (p *StudentProfile) validate() error {
+	p.normalize()
+	if err := validate.Struct(p); err != nil {
+		return err
+	}
+	return p.validateSkillLevels()
+}
+
+func dedupe(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		key := strings.ToLower(item)
+		if item == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// profileETag is the entity tag of a version of a profile
+func profileETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// maxPatchAttempts bounds how often a PATCH without If-Match is applied
+// again after a concurrent update
+const maxPatchAttempts = 3
+
+// ifMatchVersion checks the If-Match header against the current version of a
+// profile. It returns the version the update has to be conditional on, or 0
+// when the request has no If-Match header or uses "*".
+func ifMatchVersion(r *http.Request, current int) (int, error) {
+	header := strings.Join(r.Header.Values("If-Match"), ",")
+	if strings.TrimSpace(header) == "" || strings.TrimSpace(header) == "*" {
+		return 0, nil
+	}
+
+	// If-Match uses the strong comparison, weak tags never match
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == profileETag(current) {
+			return current, nil
+		}
+	}
+
+	return 0, apperr.PreconditionFailed("profile was modified, its current version is %d", current)
+}
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7386) to a profile. The
+// result is decoded as strictly as a request body; fields that can't be
+// changed by the client are kept.
+func applyMergePatch(profile StudentProfile, patch map[string]interface{}) (StudentProfile, error) {
+	raw, err := json.Marshal(profile)
+	if err != nil {
+		return StudentProfile{}, err
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return StudentProfile{}, err
+	}
+	mergePatch(doc, patch)
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return StudentProfile{}, err
+	}
+
+	var patched StudentProfile
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return StudentProfile{}, decodeError(err)
+	}
+
+	patched.ID = profile.ID
+	patched.UserID = profile.UserID
+	patched.CreatedAt = profile.CreatedAt
+	patched.UpdatedAt = profile.UpdatedAt
+	patched.Version = profile.Version
+	patched.Endorsements = profile.Endorsements
+	patched.Match = nil
+
+	return patched, nil
+}
+
+// mergePatch applies patch to doc in place: null removes a member, objects
+// are merged recursively and any other value replaces the member
+func mergePatch(doc, patch map[string]interface{}) {
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(doc, key)
+		case map[string]interface{}:
+			target, ok := doc[key].(map[string]interface{})
+			if !ok {
+				target = map[string]interface{}{}
+			}
+			mergePatch(target, value)
+			doc[key] = target
+		default:
+			doc[key] = value
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/database/memory"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func testProfile(email string) api.StudentProfile {
+	return api.StudentProfile{
+		Name:         "Test Student",
+		Email:        email,
+		Faculty:      "Engineering",
+		FieldOfStudy: "Computer Science",
+		Semester:     3,
+		Skills:       []string{"Go", "SQL"},
+		Focus:        []string{"Backend"},
+		IsAvailable:  true,
+	}
+}
+
+// createProfile creates a profile owned by the holder of token
+func (a *testAPI) createProfile(token string, profile api.StudentProfile) api.StudentProfile {
+	a.t.Helper()
+	rec := a.do("POST", "/api/profiles", token, profile)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var created api.StudentProfile
+	decode(a.t, rec, &created)
+	return created
+}
+
+// racingDB changes a profile behind the handler's back, between the read
+// and the write of an update, as a concurrent request would
+type racingDB struct {
+	*memory.MemoryDB
+	race func(id string)
+}
+
+func (d *racingDB) UpdateProfile(id string, profile *api.StudentProfile, version int) error {
+	if race := d.race; race != nil {
+		d.race = nil
+		race(id)
+	}
+	return d.MemoryDB.UpdateProfile(id, profile, version)
+}
+
+func TestPatchProfileKeepsConcurrentUpdate(t *testing.T) {
+	a := newTestAPI(t)
+	token, _ := a.register("owner@example.com")
+	created := a.createProfile(token, testProfile("owner@example.com"))
+
+	db := &racingDB{MemoryDB: a.db}
+	db.race = func(id string) {
+		concurrent, err := a.db.GetProfile(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		concurrent.Bio = "Written concurrently"
+		if err := a.db.UpdateProfile(id, &concurrent, concurrent.Version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.handler = api.NewAPIServer(db, testSecret).Handler()
+
+	rec := a.do("PATCH", "/api/profiles/"+created.ID, token, `{"is_available": false}`)
+	expectStatus(t, rec, http.StatusOK)
+
+	stored, err := a.db.GetProfile(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.IsAvailable || stored.Bio != "Written concurrently" {
+		t.Errorf("is_available = %v, bio = %q, want both updates", stored.IsAvailable, stored.Bio)
+	}
+	if stored.Version != created.Version+2 {
+		t.Errorf("version = %d, want %d", stored.Version, created.Version+2)
+	}
+}
+
+func TestPatchProfileIfMatchConflict(t *testing.T) {
+	a := newTestAPI(t)
+	token, _ := a.register("owner@example.com")
+	created := a.createProfile(token, testProfile("owner@example.com"))
+	etag := `"1"`
+
+	db := &racingDB{MemoryDB: a.db}
+	db.race = func(id string) {
+		concurrent, _ := a.db.GetProfile(id)
+		concurrent.Bio = "Written concurrently"
+		a.db.UpdateProfile(id, &concurrent, 0)
+	}
+	a.handler = api.NewAPIServer(db, testSecret).Handler()
+
+	// The version matched when the request started, but not at the write
+	rec := a.do("PATCH", "/api/profiles/"+created.ID, token, `{"is_available": false}`, "If-Match", etag)
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+
+	stored, _ := a.db.GetProfile(created.ID)
+	if !stored.IsAvailable {
+		t.Error("conditional patch was written despite the conflict")
+	}
+}
+
+func TestProfileOwnership(t *testing.T) {
+	a := newTestAPI(t)
+	ownerToken, _ := a.register("owner@example.com")
+	profile := a.createProfile(ownerToken, testProfile("owner@example.com"))
+	otherToken, _ := a.register("other@example.com")
+	moderatorToken, _ := a.registerAs("moderator@example.com", types.RoleFacultyModerator)
+
+	path := "/api/profiles/" + profile.ID
+	update := testProfile("owner@example.com")
+	update.Name = "Renamed"
+
+	expectStatus(t, a.do("PUT", path, otherToken, update), http.StatusForbidden)
+	expectStatus(t, a.do("PATCH", path, otherToken, `{"name": "Renamed"}`, "Content-Type", "application/merge-patch+json"), http.StatusForbidden)
+	expectStatus(t, a.do("DELETE", path, otherToken, nil), http.StatusForbidden)
+
+	expectStatus(t, a.do("PUT", path, ownerToken, update), http.StatusOK)
+	expectStatus(t, a.do("PATCH", path, moderatorToken, `{"bio": "Moderated"}`, "Content-Type", "application/merge-patch+json"), http.StatusOK)
+	expectStatus(t, a.do("DELETE", path, moderatorToken, nil), http.StatusNoContent)
+	expectStatus(t, a.do("GET", path, ownerToken, nil), http.StatusNotFound)
+}
@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func TestRolePermissions(t *testing.T) {
+	a := newTestAPI(t)
+	tokens := map[string]string{}
+	for _, role := range types.Roles {
+		tokens[role], _ = a.registerAs(role+"@example.com", role)
+		a.createProfile(tokens[role], testProfile(role+"@example.com"))
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		// allowed are the roles that get past the permission check
+		allowed []string
+		status  int
+	}{
+		{"create team", "POST", "/api/teams", testTeam(),
+			[]string{types.RoleTeamLead, types.RoleFacultyModerator, types.RoleAdmin}, http.StatusCreated},
+		{"list users", "GET", "/api/admin/users", nil,
+			[]string{types.RoleAdmin}, http.StatusOK},
+		{"add term", "POST", "/api/admin/skills", api.Term{Kind: api.TermSkill, Name: "Term"},
+			[]string{types.RoleFacultyModerator, types.RoleAdmin}, http.StatusCreated},
+		{"list profiles", "GET", "/api/profiles", nil,
+			types.Roles, http.StatusOK},
+	}
+	for _, tt := range tests {
+		for _, role := range types.Roles {
+			t.Run(tt.name+" as "+role, func(t *testing.T) {
+				body := tt.body
+				if term, ok := body.(api.Term); ok {
+					// Term names are unique
+					term.Name += " " + role
+					body = term
+				}
+
+				want := http.StatusForbidden
+				for _, allowed := range tt.allowed {
+					if allowed == role {
+						want = tt.status
+					}
+				}
+				expectStatus(t, a.do(tt.method, tt.path, tokens[role], body), want)
+			})
+		}
+	}
+}
+
+func TestRoleChangeNeedsNewToken(t *testing.T) {
+	a := newTestAPI(t)
+	token, user := a.register("user@example.com")
+	adminToken, _ := a.registerAs("admin@example.com", types.RoleAdmin)
+
+	rec := a.do("PUT", "/api/admin/users/"+user.ID+"/role", adminToken, map[string]string{"role": types.RoleTeamLead})
+	expectStatus(t, rec, http.StatusOK)
+
+	// Roles are part of the access token
+	expectStatus(t, a.do("POST", "/api/teams", token, testTeam()), http.StatusForbidden)
+
+	rec = a.do("PUT", "/api/admin/users/"+user.ID+"/role", adminToken, map[string]string{"role": "root"})
+	expectStatus(t, rec, http.StatusBadRequest)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"html"
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+const (
+	maxQueryLength = 200
+	maxQueryWords  = 10
+)
+
+// Tags around the matched words of highlighted snippets
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// SearchMatch is the relevance of a profile to the q of a search and the
+// fields that matched it, with the matched words highlighted
+type SearchMatch struct {
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// parseSearchFilters reads SearchFilters from a query string. List filters
+// are given by repeating the parameter, e.g. ?skills=Go&skills=Docker.
+func parseSearchFilters(query url.Values) (SearchFilters, error) {
+	filters := SearchFilters{
+		Query:         query.Get("q"),
+		Faculty:       query.Get("faculty"),
+		FieldOfStudy:  query.Get("field_of_study"),
+		Skills:        nonEmpty(query["skills"]),
+		SkillsMatch:   query.Get("skills_match"),
+		ExcludeSkills: nonEmpty(query["exclude_skills"]),
+		Focus:         nonEmpty(query["focus"]),
+		FocusMatch:    query.Get("focus_match"),
+		ExcludeFocus:  nonEmpty(query["exclude_focus"]),
+
+		MinProficiency: query.Get("min_proficiency"),
+	}
+
+	switch v := query.Get("available"); v {
+	case "", "any":
+	default:
+		available, err := strconv.ParseBool(v)
+		if err != nil {
+			return SearchFilters{}, apperr.Invalid("available", "available must be any, true or false")
+		}
+		filters.Availability = &available
+	}
+
+	var err error
+	if filters.SemesterMin, err = parseSemester(query, "semester_min"); err != nil {
+		return SearchFilters{}, err
+	}
+	if filters.SemesterMax, err = parseSemester(query, "semester_max"); err != nil {
+		return SearchFilters{}, err
+	}
+
+	if err := filters.validate(); err != nil {
+		return SearchFilters{}, err
+	}
+
+	return filters, nil
+}
+
+func (f SearchFilters) validate() error {
+	if len([]rune(f.Query)) > maxQueryLength {
+		return apperr.Invalid("q", "q must be at most %d characters", maxQueryLength)
+	}
+	if f.SemesterMin < 0 || f.SemesterMax < 0 {
+		return apperr.Invalid("semester_min", "semester bounds can't be negative")
+	}
+	if f.SemesterMin > 0 && f.SemesterMax > 0 && f.SemesterMin > f.SemesterMax {
+		return apperr.Invalid("semester_min", "semester_min can't be greater than semester_max")
+	}
+	if !isValidMatch(f.SkillsMatch) {
+		return apperr.Invalid("skills_match", "skills_match must be any or all")
+	}
+	if !isValidMatch(f.FocusMatch) {
+		return apperr.Invalid("focus_match", "focus_match must be any or all")
+	}
+	if f.MinProficiency != "" {
+		if ProficiencyRank(f.MinProficiency) == 0 {
+			return apperr.Invalid("min_proficiency", "min_proficiency must be one of %s", strings.Join(ProficiencyLevels, ", "))
+		}
+		if len(f.Skills) == 0 {
+			return apperr.Invalid("min_proficiency", "min_proficiency requires skills")
+		}
+	}
+	return nil
+}
+
+// parseSearchListOptions is parseListOptions for profile searches, which
+// are sorted by relevance by default when they have a q
+func parseSearchListOptions(query url.Values, filters SearchFilters) (ListOptions, error) {
+	hasWords := len(QueryWords(filters.Query)) > 0
+	if hasWords && query.Get("sort") == "" {
+		query = maps.Clone(query)
+		query.Set("sort", SortRelevance)
+	}
+
+	opts, err := parseListOptions(query)
+	if err != nil {
+		return ListOptions{}, err
+	}
+	if opts.Sort == SortRelevance && !hasWords {
+		return ListOptions{}, apperr.Invalid("sort", "sort by relevance requires q")
+	}
+	return opts, nil
+}
+
+// QueryWords splits a full-text query into lower-cased words, dropping
+// punctuation and anything past the first maxQueryWords words
+func QueryWords(q string) []string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxQueryWords {
+		words = words[:maxQueryWords]
+	}
+	return words
+}
+
+// Highlight escapes a snippet for HTML, except for the <mark> tags around
+// the matched words
+func Highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, html.EscapeString(HighlightStart), HighlightStart)
+	return strings.ReplaceAll(snippet, html.EscapeString(HighlightStop), HighlightStop)
+}
+
+// isValidMatch accepts an empty mode, which means MatchAny
+func isValidMatch(mode string) bool {
+	return mode == "" || mode == MatchAny || mode == MatchAll
+}
+
+func parseSemester(query url.Values, key string) (int, error) {
+	v := query.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+
+	semester, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, apperr.Invalid(key, "%s must be an integer", key)
+	}
+	return semester, nil
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
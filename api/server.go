@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/listen"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+)
+
+// adminPathPrefix is served by the admin listener when there is one
+const adminPathPrefix = "/api/admin/"
+
+// ServerOptions configures the HTTP server started by APIServer.Start
+type ServerOptions struct {
+	// Addrs are the addresses to listen on, in the formats of package listen
+	Addrs []string
+	// TLS serves HTTPS instead of HTTP on Addrs when set
+	TLS *tls.Config
+	// AdminAddrs are internal addresses for the /api/admin routes, which
+	// are no longer served on Addrs when set. AdminTLS is their TLS
+	// configuration, e.g. one requiring client certificates.
+	AdminAddrs []string
+	AdminTLS   *tls.Config
+	// SocketMode is the file mode of Unix sockets
+	SocketMode        fs.FileMode
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownTimeout is how long in-flight requests get to finish once ctx
+	// is done. Requests still running after it are cut off.
+	ShutdownTimeout time.Duration
+}
+
+// Start serves the API on every address until ctx is done and then shuts
+// down gracefully: it stops accepting connections and waits for in-flight
+// requests to finish, for at most opts.ShutdownTimeout. If one listener
+// fails the others are shut down the same way.
+func (s *APIServer) Start(ctx context.Context, opts ServerOptions) error {
+	split := len(opts.AdminAddrs) > 0
+
+	public := s.httpServer(s.handler(split, false), opts.TLS, opts)
+	listeners, err := listen.Listen(opts.Addrs, opts.SocketMode)
+	if err != nil {
+		return err
+	}
+
+	var adminListeners []net.Listener
+	if split {
+		if adminListeners, err = listen.Listen(opts.AdminAddrs, opts.SocketMode); err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+	}
+
+	// Every serve goroutine sends at most one error, and one address can
+	// have several listeners, e.g. systemd
+	servers := []*http.Server{public}
+	serveErr := make(chan error, len(listeners)+len(adminListeners))
+	serve(public, listeners, "Server", serveErr)
+
+	if split {
+		admin := s.httpServer(s.handler(split, true), opts.AdminTLS, opts)
+		servers = append(servers, admin)
+		serve(admin, adminListeners, "Admin server", serveErr)
+	}
+
+	var failed error
+	select {
+	case failed = <-serveErr:
+		logger.Error("Listener failed", "error", failed)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", opts.ShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	// Drain all servers at the same time so that they share the deadline
+	var wg sync.WaitGroup
+	shutdownErrs := make([]error, len(servers))
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				server.Close()
+				shutdownErrs[i] = fmt.Errorf("failed to drain in-flight requests: %w", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(shutdownErrs...); err != nil {
+		return errors.Join(failed, err)
+	}
+
+	logger.Info("Server stopped")
+	return failed
+}
+
+func (s *APIServer) httpServer(handler http.Handler, tlsConfig *tls.Config, opts ServerOptions) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+		// Failed TLS handshakes and the like, not worth more than a warning
+		ErrorLog: logging.StdLogger("http", slog.LevelWarn),
+	}
+}
+
+// Handler serves the whole API the way Start does on a single listener,
+// e.g. for tests with httptest
+func (s *APIServer) Handler() http.Handler {
+	return s.handler(false, false)
+}
+
+// handler serves the whole API, or with split only the admin routes on the
+// admin server and everything else on the public one. Every request gets an
+// ID and is logged, including those no route matches. CORS is handled
+// outside the router, whose middleware only runs on matched routes: a
+// preflight OPTIONS request matches none of them.
+func (s *APIServer) handler(split bool, admin bool) http.Handler {
+	var handler http.Handler = s.router
+	if split {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, adminPathPrefix) != admin {
+				s.router.NotFoundHandler.ServeHTTP(w, r)
+				return
+			}
+			s.router.ServeHTTP(w, r)
+		})
+	}
+	return middleware.RequestID(middleware.Logger(middleware.CORS(handler)))
+}
+
+// serve runs server on every listener, HTTPS if it has a TLS configuration
+func serve(server *http.Server, listeners []net.Listener, name string, serveErr chan<- error) {
+	scheme := "http"
+	if server.TLSConfig != nil {
+		scheme = "https"
+	}
+
+	for _, ln := range listeners {
+		logger.Info(name+" listening", "network", ln.Addr().Network(), "addr", ln.Addr().String(), "scheme", scheme)
+		go func() {
+			if server.TLSConfig != nil {
+				// The certificate comes from TLSConfig.GetCertificate
+				serveErr <- server.ServeTLS(ln, "", "")
+				return
+			}
+			serveErr <- server.Serve(ln)
+		}()
+	}
+}
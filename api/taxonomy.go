@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+)
+
+// Kinds of taxonomy terms, matching the StudentProfile lists they describe
+const (
+	TermSkill = "skill"
+	TermFocus = "focus"
+)
+
+const (
+	defaultTermLimit = 10
+	maxTermLimit     = 50
+)
+
+// Term is a canonical skill or focus area. Aliases are other spellings of
+// the name ("Golang" for "Go") and synonyms other words with the same
+// meaning ("Leadership" for "Team Leadership"); profiles using either are
+// rewritten to Name.
+type Term struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind" validate:"required"`
+	Name      string   `json:"name" validate:"required,max=50"`
+	Category  string   `json:"category" validate:"max=50"`
+	Aliases   []string `json:"aliases" validate:"max=20,itemmax=50"`
+	Synonyms  []string `json:"synonyms" validate:"max=20,itemmax=50"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// MergeTermsRequest names the term another one is merged into
+type MergeTermsRequest struct {
+	Into string `json:"into" validate:"required"`
+}
+
+// MergeResult is the surviving term of a merge and how many profiles and
+// teams were re-tagged
+type MergeResult struct {
+	Term Term `json:"term"`
+	RetagResult
+}
+
+// RetagResult reports how many profiles and teams a re-tag changed
+type RetagResult struct {
+	Retagged      int `json:"retagged"`
+	RetaggedTeams int `json:"retagged_teams"`
+}
+
+// TermLookup maps the lower-cased names, aliases and synonyms of the terms
+// of one kind to their canonical names
+type TermLookup map[string]string
+
+func isValidTermKind(kind string) bool {
+	return kind == TermSkill || kind == TermFocus
+}
+
+// validate trims the term and checks that its names don't repeat each other
+func (t *Term) validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Category = strings.TrimSpace(t.Category)
+	t.Aliases = dedupe(t.Aliases)
+	t.Synonyms = dedupe(t.Synonyms)
+
+	if err := validate.Struct(t); err != nil {
+		return err
+	}
+	if !isValidTermKind(t.Kind) {
+		return apperr.Invalid("kind", "kind must be skill or focus")
+	}
+
+	seen := map[string]bool{strings.ToLower(t.Name): true}
+	for _, name := range append(append([]string{}, t.Aliases...), t.Synonyms...) {
+		if seen[strings.ToLower(name)] {
+			return apperr.Invalid("aliases", "%q is used more than once as name, alias or synonym", name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+
+	return nil
+}
+
+// Name returns the canonical name of term, or term itself when it isn't in
+// the taxonomy
+func (l TermLookup) Name(term string) string {
+	term = strings.TrimSpace(term)
+	if name, ok := l[strings.ToLower(term)]; ok {
+		return name
+	}
+	return term
+}
+
+// Canonical rewrites terms to their canonical names. Terms that aren't in
+// the taxonomy are kept as typed; duplicates created by the rewrite are
+// dropped.
+func (l TermLookup) Canonical(terms []string) []string {
+	canonical := make([]string, 0, len(terms))
+	for _, term := range terms {
+		canonical = append(canonical, l.Name(term))
+	}
+	return dedupe(canonical)
+}
+
+// parseTermQuery reads kind, prefix and limit of the autocomplete endpoint
+func parseTermQuery(query url.Values) (kind string, prefix string, limit int, err error) {
+	kind = TermSkill
+	if v := query.Get("kind"); v != "" {
+		if !isValidTermKind(v) {
+			return "", "", 0, apperr.Invalid("kind", "kind must be skill or focus")
+		}
+		kind = v
+	}
+
+	limit = defaultTermLimit
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return "", "", 0, apperr.Invalid("limit", "limit must be a positive integer")
+		}
+		limit = min(limit, maxTermLimit)
+	}
+
+	return kind, strings.TrimSpace(query.Get("prefix")), limit, nil
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+)
+
+// handleSearchTerms autocompletes skills, or focus areas with ?kind=focus
+func (s *APIServer) handleSearchTerms(w http.ResponseWriter, r *http.Request) {
+	kind, prefix, limit, err := parseTermQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	terms, err := s.db.SearchTerms(kind, prefix, limit)
+	if err != nil {
+		writeError(w, r, err, "Failed to search skills")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(terms)
+}
+
+func (s *APIServer) handleCreateTerm(w http.ResponseWriter, r *http.Request) {
+	var term Term
+	if err := decodeJSON(w, r, &term); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+	if err := term.validate(); err != nil {
+		writeError(w, r, err, "Invalid term")
+		return
+	}
+
+	if err := s.db.CreateTerm(&term); err != nil {
+		writeError(w, r, err, "Failed to create term")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(term)
+}
+
+// handleUpdateTerm replaces the name, category, aliases and synonyms of a
+// term. Profiles keep the old name until they are re-tagged.
+func (s *APIServer) handleUpdateTerm(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	existing, err := s.db.GetTerm(id)
+	if err != nil {
+		writeError(w, r, err, "Failed to update term")
+		return
+	}
+
+	var term Term
+	if err := decodeJSON(w, r, &term); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+	// A term can't move between skills and focus areas
+	term.Kind = existing.Kind
+	if err := term.validate(); err != nil {
+		writeError(w, r, err, "Invalid term")
+		return
+	}
+
+	if err := s.db.UpdateTerm(id, &term); err != nil {
+		writeError(w, r, err, "Failed to update term")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(term)
+}
+
+func (s *APIServer) handleDeleteTerm(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.DeleteTerm(mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, err, "Failed to delete term")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMergeTerms merges a duplicate term into the one named by "into"
+func (s *APIServer) handleMergeTerms(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req MergeTermsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(&req); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+	if req.Into == id {
+		writeProblem(w, r, "A term can't be merged into itself", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.db.MergeTerms(id, req.Into)
+	if err != nil {
+		writeError(w, r, err, "Failed to merge terms")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleRetag applies the current taxonomy to every existing profile and
+// team, e.g. after adding aliases
+func (s *APIServer) handleRetag(w http.ResponseWriter, r *http.Request) {
+	result, err := s.db.Retag()
+	if err != nil {
+		writeError(w, r, err, "Failed to re-tag profiles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// termLookups holds the skill and focus lookups, loaded once per request
+type termLookups struct {
+	skills, focus TermLookup
+}
+
+func (s *APIServer) loadTermLookups() (termLookups, error) {
+	skills, err := s.db.TermLookup(TermSkill)
+	if err != nil {
+		return termLookups{}, err
+	}
+	focus, err := s.db.TermLookup(TermFocus)
+	if err != nil {
+		return termLookups{}, err
+	}
+	return termLookups{skills: skills, focus: focus}, nil
+}
+
+// canonicalizeProfile rewrites the skills and focus areas of a profile to
+// their canonical names
+func (l termLookups) canonicalizeProfile(profile *StudentProfile) {
+	profile.Skills = l.skills.Canonical(profile.Skills)
+	profile.SkillLevels = l.skills.CanonicalLevels(profile.SkillLevels)
+	profile.Focus = l.focus.Canonical(profile.Focus)
+}
+
+// canonicalizeFilters does the same for search filters, so that searching
+// for "golang" finds profiles tagged "Go"
+func (l termLookups) canonicalizeFilters(filters *SearchFilters) {
+	filters.Skills = l.skills.Canonical(filters.Skills)
+	filters.ExcludeSkills = l.skills.Canonical(filters.ExcludeSkills)
+	filters.Focus = l.focus.Canonical(filters.Focus)
+	filters.ExcludeFocus = l.focus.Canonical(filters.ExcludeFocus)
+}
+
+// canonicalizeTeam rewrites the required skills and focus areas of a team
+// like canonicalizeProfile, so that teams and profiles use the same names
+func (l termLookups) canonicalizeTeam(team *Team) {
+	team.RequiredSkills = l.skills.Canonical(team.RequiredSkills)
+	team.Focus = l.focus.Canonical(team.Focus)
+}
+
+// canonicalizeTeamFilters is canonicalizeFilters for team searches
+func (l termLookups) canonicalizeTeamFilters(filters *TeamSearchFilters) {
+	filters.Skills = l.skills.Canonical(filters.Skills)
+	filters.Focus = l.focus.Canonical(filters.Focus)
+}
+
+// canonicalizeMatch rewrites the terms of a match request, so that asking
+// for "golang" matches profiles tagged "Go"
+func (l termLookups) canonicalizeMatch(req *MatchRequest) {
+	req.Skills = l.skills.CanonicalWeighted(req.Skills)
+	req.Focus = l.focus.CanonicalWeighted(req.Focus)
+}
@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// addGo adds the skill Go with the alias Golang to the taxonomy
+func (a *testAPI) addGo() {
+	a.t.Helper()
+	term := &api.Term{Kind: api.TermSkill, Name: "Go", Aliases: []string{"Golang"}}
+	if err := a.db.CreateTerm(term); err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+func TestTeamSkillsAreCanonicalized(t *testing.T) {
+	a := newTestAPI(t)
+	a.addGo()
+	token := a.teamLead("lead@example.com")
+
+	team := testTeam()
+	team.RequiredSkills = []string{"golang", "GO", "React"}
+	created := a.createTeam(token, team)
+	if len(created.RequiredSkills) != 2 || created.RequiredSkills[0] != "Go" {
+		t.Fatalf("required_skills = %q, want [Go React]", created.RequiredSkills)
+	}
+
+	rec := a.do("GET", "/api/teams/search?skills=GOLANG", token, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var page api.TeamPage
+	decode(t, rec, &page)
+	if len(page.Items) != 1 || page.Items[0].ID != created.ID {
+		t.Errorf("search for GOLANG found %d teams, want the created one", len(page.Items))
+	}
+}
+
+func TestMatchTermsAreCanonicalized(t *testing.T) {
+	a := newTestAPI(t)
+	a.addGo()
+	studentToken, _ := a.register("student@example.com")
+	profile := a.createProfile(studentToken, testProfile("student@example.com"))
+	token, _ := a.register("lead@example.com")
+
+	req := api.MatchRequest{Skills: []api.WeightedTerm{{Name: "golang", Weight: 2}, {Name: "Go"}}}
+	rec := a.do("POST", "/api/profiles/match", token, req)
+	expectStatus(t, rec, http.StatusOK)
+
+	var results []api.MatchResult
+	decode(t, rec, &results)
+	if len(results) != 1 || results[0].Profile.ID != profile.ID {
+		t.Fatalf("got %d results, want the profile", len(results))
+	}
+	if got := results[0].MatchedSkills; len(got) != 1 || got[0] != "Go" {
+		t.Errorf("matched_skills = %q, want [Go]", got)
+	}
+}
+
+func TestMergeRetagsTeams(t *testing.T) {
+	a := newTestAPI(t)
+	token := a.teamLead("lead@example.com")
+	team := testTeam()
+	team.RequiredSkills = []string{"Golang"}
+	created := a.createTeam(token, team)
+
+	// Golang started out as a term of its own and turns out to be Go
+	golang := &api.Term{Kind: api.TermSkill, Name: "Golang"}
+	goTerm := &api.Term{Kind: api.TermSkill, Name: "Go"}
+	for _, term := range []*api.Term{golang, goTerm} {
+		if err := a.db.CreateTerm(term); err != nil {
+			t.Fatal(err)
+		}
+	}
+	adminToken, _ := a.registerAs("admin@example.com", types.RoleAdmin)
+	rec := a.do("POST", "/api/admin/skills/"+golang.ID+"/merge", adminToken, api.MergeTermsRequest{Into: goTerm.ID})
+	expectStatus(t, rec, http.StatusOK)
+	var result api.MergeResult
+	decode(t, rec, &result)
+	if result.RetaggedTeams != 1 {
+		t.Errorf("retagged_teams = %d, want 1", result.RetaggedTeams)
+	}
+
+	for _, skill := range []string{"Go", "golang"} {
+		rec = a.do("GET", "/api/teams/search?skills="+skill, token, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var page api.TeamPage
+		decode(t, rec, &page)
+		if len(page.Items) != 1 || page.Items[0].ID != created.ID {
+			t.Errorf("search for %s found %d teams, want the created one", skill, len(page.Items))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+)
+
+const (
+	TeamStatusOpen   = "open"
+	TeamStatusFull   = "full"
+	TeamStatusClosed = "closed"
+)
+
+const (
+	TeamRoleOwner  = "owner"
+	TeamRoleMember = "member"
+)
+
+// Team is a group of students looking for members. OwnerID is the user who
+// created it. Status is "full" whenever MemberCount reaches MaxSize, unless
+// the owner closed the team.
+type Team struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name" validate:"required,max=100"`
+	Description    string   `json:"description" validate:"max=2000"`
+	OwnerID        string   `json:"owner_id"`
+	RequiredSkills []string `json:"required_skills" validate:"max=30,itemmax=50"`
+	Focus          []string `json:"focus" validate:"max=10,itemmax=50"`
+	MaxSize        int      `json:"max_size" validate:"min=2,max=50"`
+	MemberCount    int      `json:"member_count"`
+	Status         string   `json:"status"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+type TeamMember struct {
+	TeamID    string `json:"team_id"`
+	ProfileID string `json:"profile_id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	JoinedAt  string `json:"joined_at"`
+}
+
+type TeamSearchFilters struct {
+	Skills      []string `json:"skills"`
+	SkillsMatch string   `json:"skills_match"`
+	Focus       []string `json:"focus"`
+	FocusMatch  string   `json:"focus_match"`
+	Status      string   `json:"status"`
+}
+
+type TeamPage = Page[Team]
+
+// NewTeamPage is NewProfilePage for teams
+func NewTeamPage(opts ListOptions, teams []Team, total int) TeamPage {
+	return newPage(opts, teams, total, func(t Team) (string, string) {
+		if opts.Sort == SortName {
+			return t.Name, t.ID
+		}
+		return t.CreatedAt, t.ID
+	})
+}
+
+// normalize trims the text fields and removes blank and duplicate skills and
+// focus areas, like StudentProfile.normalize
+func (t *Team) normalize() {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Description = strings.TrimSpace(t.Description)
+	t.RequiredSkills = dedupe(t.RequiredSkills)
+	t.Focus = dedupe(t.Focus)
+}
+
+// validate normalizes the team and reports every rule it breaks, see the
+// validate tags of Team
+func (t *Team) validate() error {
+	t.normalize()
+	if err := validate.Struct(t); err != nil {
+		return err
+	}
+	// "full" is derived from the member count and can't be set directly
+	if t.Status == "" {
+		t.Status = TeamStatusOpen
+	}
+	if t.Status != TeamStatusOpen && t.Status != TeamStatusClosed {
+		return apperr.Invalid("status", "status must be open or closed")
+	}
+	return nil
+}
+
+// parseTeamSearchFilters reads TeamSearchFilters from a query string the same
+// way parseSearchFilters does for profiles. defaultStatus applies when no
+// status is given; "any" matches every status.
+func parseTeamSearchFilters(query url.Values, defaultStatus string) (TeamSearchFilters, error) {
+	filters := TeamSearchFilters{
+		Skills:      nonEmpty(query["skills"]),
+		SkillsMatch: query.Get("skills_match"),
+		Focus:       nonEmpty(query["focus"]),
+		FocusMatch:  query.Get("focus_match"),
+		Status:      query.Get("status"),
+	}
+
+	if filters.Status == "" {
+		filters.Status = defaultStatus
+	}
+	if filters.Status == "any" {
+		filters.Status = ""
+	}
+
+	switch filters.Status {
+	case "", TeamStatusOpen, TeamStatusFull, TeamStatusClosed:
+	default:
+		return TeamSearchFilters{}, apperr.Invalid("status", "status must be any, open, full or closed")
+	}
+
+	if !isValidMatch(filters.SkillsMatch) {
+		return TeamSearchFilters{}, apperr.Invalid("skills_match", "skills_match must be any or all")
+	}
+	if !isValidMatch(filters.FocusMatch) {
+		return TeamSearchFilters{}, apperr.Invalid("focus_match", "focus_match must be any or all")
+	}
+
+	return filters, nil
+}
+
+// parseTeamListOptions is parseListOptions restricted to the sort keys teams have
+func parseTeamListOptions(query url.Values) (ListOptions, error) {
+	opts, err := parseListOptions(query)
+	if err != nil {
+		return ListOptions{}, err
+	}
+	if opts.Sort == SortSemester || opts.Sort == SortRelevance {
+		return ListOptions{}, apperr.Invalid("sort", "sort must be created_at or name")
+	}
+	return opts, nil
+}
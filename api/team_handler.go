@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func (s *APIServer) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.UserFromContext(r.Context())
+
+	var team Team
+	if err := decodeJSON(w, r, &team); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if err := team.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to create team")
+		return
+	}
+	terms.canonicalizeTeam(&team)
+
+	// The owner joins their own team, so they need a profile first
+	profile, err := s.db.GetProfileByUserID(user.ID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			writeProblem(w, r, "Create your profile before creating a team", http.StatusConflict)
+			return
+		}
+		writeError(w, r, err, "Failed to create team")
+		return
+	}
+
+	team.OwnerID = user.ID
+
+	if err := s.db.CreateTeam(&team, profile.ID); err != nil {
+		writeError(w, r, err, "Failed to create team")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(team)
+}
+
+func (s *APIServer) handleGetTeam(w http.ResponseWriter, r *http.Request) {
+	team, err := s.db.GetTeam(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, err, "Failed to get team")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+func (s *APIServer) handleUpdateTeam(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if _, ok := s.authorizeTeamWrite(w, r, id); !ok {
+		return
+	}
+
+	var team Team
+	if err := decodeJSON(w, r, &team); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if err := team.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to update team")
+		return
+	}
+	terms.canonicalizeTeam(&team)
+
+	if err := s.db.UpdateTeam(id, &team); err != nil {
+		writeError(w, r, err, "Failed to update team")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+func (s *APIServer) handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if _, ok := s.authorizeTeamWrite(w, r, id); !ok {
+		return
+	}
+
+	if err := s.db.DeleteTeam(id); err != nil {
+		writeError(w, r, err, "Failed to delete team")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetAllTeams lists teams of any status unless ?status= is given
+func (s *APIServer) handleGetAllTeams(w http.ResponseWriter, r *http.Request) {
+	s.searchTeams(w, r, "any")
+}
+
+// handleSearchTeams searches open teams unless ?status= says otherwise
+func (s *APIServer) handleSearchTeams(w http.ResponseWriter, r *http.Request) {
+	s.searchTeams(w, r, TeamStatusOpen)
+}
+
+func (s *APIServer) searchTeams(w http.ResponseWriter, r *http.Request, defaultStatus string) {
+	filters, err := parseTeamSearchFilters(r.URL.Query(), defaultStatus)
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+	terms, err := s.loadTermLookups()
+	if err != nil {
+		writeError(w, r, err, "Failed to search teams")
+		return
+	}
+	terms.canonicalizeTeamFilters(&filters)
+
+	opts, err := parseTeamListOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	page, err := s.db.SearchTeams(filters, opts)
+	if err != nil {
+		writeError(w, r, err, "Failed to search teams")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *APIServer) handleGetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if _, err := s.db.GetTeam(id); err != nil {
+		writeError(w, r, err, "Failed to get team members")
+		return
+	}
+
+	members, err := s.db.GetTeamMembers(id)
+	if err != nil {
+		writeError(w, r, err, "Failed to get team members")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// handleRemoveTeamMember lets the team owner remove a member, or a member
+// leave the team. The owner can't leave; they have to delete the team.
+func (s *APIServer) handleRemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, profileID := vars["id"], vars["profileID"]
+
+	team, err := s.db.GetTeam(teamID)
+	if err != nil {
+		writeError(w, r, err, "Failed to remove team member")
+		return
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	allowed := team.OwnerID == user.ID || types.HasPermission(user.Role, types.PermTeamModerate)
+	if !allowed {
+		profile, err := s.db.GetProfileByUserID(user.ID)
+		allowed = err == nil && profile.ID == profileID
+	}
+	if !allowed {
+		writeProblem(w, r, "You are not allowed to remove this member", http.StatusForbidden)
+		return
+	}
+
+	if err := s.db.RemoveTeamMember(teamID, profileID); err != nil {
+		writeError(w, r, err, "Failed to remove team member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeTeamWrite loads the team and checks that the caller owns it or
+// may moderate any team, like authorizeProfileWrite does for profiles
+func (s *APIServer) authorizeTeamWrite(w http.ResponseWriter, r *http.Request, id string) (Team, bool) {
+	team, err := s.db.GetTeam(id)
+	if err != nil {
+		writeError(w, r, err, "Failed to get team")
+		return Team{}, false
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	if team.OwnerID != user.ID && !types.HasPermission(user.Role, types.PermTeamModerate) {
+		writeProblem(w, r, "You are not allowed to modify this team", http.StatusForbidden)
+		return Team{}, false
+	}
+
+	return team, true
+}
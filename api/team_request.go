@@ -0,0 +1,96 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/validate"
+)
+
+// Kinds of TeamRequest
+const (
+	TeamRequestInvitation = "invitation"
+	TeamRequestJoin       = "join_request"
+)
+
+// States of a TeamRequest. Every request starts pending and moves to exactly
+// one of the other states.
+const (
+	RequestPending   = "pending"
+	RequestAccepted  = "accepted"
+	RequestDeclined  = "declined"
+	RequestExpired   = "expired"
+	RequestWithdrawn = "withdrawn"
+)
+
+// Directions of ListTeamRequests, seen from the caller
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+// TeamRequestTTL is how long a request stays pending before it expires
+const TeamRequestTTL = 14 * 24 * time.Hour
+
+// TeamRequest is either an invitation sent by a team owner to a profile or a
+// join request sent by a student to a team. ProfileID is the invited or
+// requesting profile in both cases.
+type TeamRequest struct {
+	ID        string `json:"id"`
+	TeamID    string `json:"team_id"`
+	TeamName  string `json:"team_name"`
+	ProfileID string `json:"profile_id"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	CreatedBy string `json:"created_by"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TeamRequestFilter selects the requests of a user. Incoming requests are
+// invitations to ProfileID and join requests to teams owned by OwnerID;
+// outgoing requests are the other way round.
+type TeamRequestFilter struct {
+	ProfileID string
+	OwnerID   string
+	Direction string
+	Status    string
+}
+
+type CreateInvitationRequest struct {
+	ProfileID string `json:"profile_id" validate:"required"`
+	Message   string `json:"message" validate:"max=500"`
+}
+
+type CreateJoinRequest struct {
+	Message string `json:"message" validate:"max=500"`
+}
+
+// validate trims the message and reports every rule the invitation breaks
+func (req *CreateInvitationRequest) validate() error {
+	req.Message = strings.TrimSpace(req.Message)
+	return validate.Struct(req)
+}
+
+// validate trims the message and checks its length
+func (req *CreateJoinRequest) validate() error {
+	req.Message = strings.TrimSpace(req.Message)
+	return validate.Struct(req)
+}
+
+// teamRequestActions maps the actions of the API to the state they lead to
+var teamRequestActions = map[string]string{
+	"accept":   RequestAccepted,
+	"decline":  RequestDeclined,
+	"withdraw": RequestWithdrawn,
+}
+
+func isValidRequestStatus(status string) bool {
+	switch status {
+	case RequestPending, RequestAccepted, RequestDeclined, RequestExpired, RequestWithdrawn:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/middleware"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// handleCreateInvitation lets the owner of a team invite a profile
+func (s *APIServer) handleCreateInvitation(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.UserFromContext(r.Context())
+
+	team, ok := s.authorizeTeamWrite(w, r, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	var body CreateInvitationRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if err := body.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	if team.Status != TeamStatusOpen {
+		writeProblem(w, r, "Team is not open for new members", http.StatusConflict)
+		return
+	}
+
+	if _, err := s.db.GetProfile(body.ProfileID); err != nil {
+		writeError(w, r, err, "Failed to create invitation")
+		return
+	}
+
+	s.createTeamRequest(w, r, &TeamRequest{
+		TeamID:    team.ID,
+		ProfileID: body.ProfileID,
+		Kind:      TeamRequestInvitation,
+		Message:   body.Message,
+		CreatedBy: user.ID,
+	})
+}
+
+// handleCreateJoinRequest lets a student ask to join an open team
+func (s *APIServer) handleCreateJoinRequest(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.UserFromContext(r.Context())
+
+	var body CreateJoinRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeError(w, r, err, "Invalid request body")
+		return
+	}
+
+	if err := body.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	team, err := s.db.GetTeam(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, err, "Failed to create join request")
+		return
+	}
+
+	if team.Status != TeamStatusOpen {
+		writeProblem(w, r, "Team is not open for new members", http.StatusConflict)
+		return
+	}
+
+	profile, err := s.db.GetProfileByUserID(user.ID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			writeProblem(w, r, "Create your profile before joining a team", http.StatusConflict)
+			return
+		}
+		writeError(w, r, err, "Failed to create join request")
+		return
+	}
+
+	s.createTeamRequest(w, r, &TeamRequest{
+		TeamID:    team.ID,
+		ProfileID: profile.ID,
+		Kind:      TeamRequestJoin,
+		Message:   body.Message,
+		CreatedBy: user.ID,
+	})
+}
+
+func (s *APIServer) createTeamRequest(w http.ResponseWriter, r *http.Request, req *TeamRequest) {
+	if err := s.db.CreateTeamRequest(req); err != nil {
+		writeError(w, r, err, "Failed to create request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(req)
+}
+
+// handleTeamRequestAction accepts, declines or withdraws a request. The
+// invited profile answers invitations and the team owner answers join
+// requests; whoever sent a request may withdraw it. Repeating an action that
+// already happened returns the request unchanged.
+func (s *APIServer) handleTeamRequestAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	action := vars["action"]
+
+	status, ok := teamRequestActions[action]
+	if !ok {
+		writeProblem(w, r, "Unknown action", http.StatusNotFound)
+		return
+	}
+
+	req, err := s.db.GetTeamRequest(vars["id"])
+	if err != nil {
+		writeError(w, r, err, "Failed to update request")
+		return
+	}
+
+	team, err := s.db.GetTeam(req.TeamID)
+	if err != nil {
+		writeError(w, r, err, "Failed to update request")
+		return
+	}
+
+	user, _ := middleware.UserFromContext(r.Context())
+	teamSide := team.OwnerID == user.ID || types.HasPermission(user.Role, types.PermTeamModerate)
+	profileSide := false
+	profile, err := s.db.GetProfileByUserID(user.ID)
+	if err == nil {
+		profileSide = profile.ID == req.ProfileID
+	} else if !errors.Is(err, apperr.ErrNotFound) {
+		writeError(w, r, err, "Failed to update request")
+		return
+	}
+
+	// The side that received the request answers it, the sender withdraws it
+	receiver, sender := profileSide, teamSide
+	if req.Kind == TeamRequestJoin {
+		receiver, sender = teamSide, profileSide
+	}
+
+	allowed := receiver
+	if status == RequestWithdrawn {
+		allowed = sender
+	}
+	if !allowed {
+		writeProblem(w, r, "You are not allowed to "+action+" this request", http.StatusForbidden)
+		return
+	}
+
+	updated, err := s.db.TransitionTeamRequest(req.ID, status)
+	if err != nil {
+		writeError(w, r, err, "Failed to update request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleListTeamRequests lists the caller's incoming or outgoing requests,
+// optionally filtered by ?status=
+func (s *APIServer) handleListTeamRequests(w http.ResponseWriter, r *http.Request) {
+	user, _ := middleware.UserFromContext(r.Context())
+
+	filter := TeamRequestFilter{
+		OwnerID:   user.ID,
+		Direction: mux.Vars(r)["direction"],
+		Status:    r.URL.Query().Get("status"),
+	}
+
+	if filter.Status != "" && !isValidRequestStatus(filter.Status) {
+		writeProblem(w, r, "status must be pending, accepted, declined, expired or withdrawn", http.StatusBadRequest)
+		return
+	}
+
+	// Users without a profile can still own teams
+	if profile, err := s.db.GetProfileByUserID(user.ID); err == nil {
+		filter.ProfileID = profile.ID
+	} else if !errors.Is(err, apperr.ErrNotFound) {
+		writeError(w, r, err, "Failed to fetch requests")
+		return
+	}
+
+	requests, err := s.db.ListTeamRequests(filter)
+	if err != nil {
+		writeError(w, r, err, "Failed to fetch requests")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/database/memory"
+)
+
+// teamRequestSetup has a team with one member, its owner, and a student
+// with a profile who isn't in it
+type teamRequestSetup struct {
+	*testAPI
+	team          api.Team
+	ownerToken    string
+	studentToken  string
+	student       api.StudentProfile
+	outsiderToken string
+}
+
+func newTeamRequestSetup(t *testing.T) *teamRequestSetup {
+	a := newTestAPI(t)
+	s := &teamRequestSetup{testAPI: a}
+	s.ownerToken = a.teamLead("owner@example.com")
+	team := testTeam()
+	team.MaxSize = 2
+	s.team = a.createTeam(s.ownerToken, team)
+
+	s.studentToken, _ = a.register("student@example.com")
+	s.student = a.createProfile(s.studentToken, testProfile("student@example.com"))
+
+	s.outsiderToken, _ = a.register("outsider@example.com")
+	a.createProfile(s.outsiderToken, testProfile("outsider@example.com"))
+	return s
+}
+
+func (s *teamRequestSetup) invite() api.TeamRequest {
+	s.t.Helper()
+	rec := s.do("POST", "/api/teams/"+s.team.ID+"/invitations", s.ownerToken, api.CreateInvitationRequest{ProfileID: s.student.ID, Message: "Join us"})
+	expectStatus(s.t, rec, http.StatusCreated)
+	var req api.TeamRequest
+	decode(s.t, rec, &req)
+	return req
+}
+
+func (s *teamRequestSetup) requestToJoin() api.TeamRequest {
+	s.t.Helper()
+	rec := s.do("POST", "/api/teams/"+s.team.ID+"/join-requests", s.studentToken, api.CreateJoinRequest{})
+	expectStatus(s.t, rec, http.StatusCreated)
+	var req api.TeamRequest
+	decode(s.t, rec, &req)
+	return req
+}
+
+// act performs action on a request and returns the response status and the
+// request's status afterwards
+func (s *teamRequestSetup) act(token string, req api.TeamRequest, action string) (int, string) {
+	s.t.Helper()
+	rec := s.do("POST", "/api/team-requests/"+req.ID+"/"+action, token, nil)
+	if rec.Code != http.StatusOK {
+		return rec.Code, ""
+	}
+	var updated api.TeamRequest
+	decode(s.t, rec, &updated)
+	return rec.Code, updated.Status
+}
+
+func (s *teamRequestSetup) memberCount() int {
+	s.t.Helper()
+	rec := s.do("GET", "/api/teams/"+s.team.ID, s.ownerToken, nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	var team api.Team
+	decode(s.t, rec, &team)
+	return team.MemberCount
+}
+
+func TestTeamRequestTransitions(t *testing.T) {
+	type step struct {
+		actor  string
+		action string
+		status int
+		state  string
+	}
+	tests := []struct {
+		name    string
+		join    bool
+		steps   []step
+		members int
+	}{
+		{"invitation accepted", false, []step{
+			{"owner", "accept", http.StatusForbidden, ""},
+			{"outsider", "accept", http.StatusForbidden, ""},
+			{"student", "accept", http.StatusOK, api.RequestAccepted},
+			// Repeating the action is a no-op, other transitions conflict
+			{"student", "accept", http.StatusOK, api.RequestAccepted},
+			{"student", "decline", http.StatusConflict, ""},
+			{"owner", "withdraw", http.StatusConflict, ""},
+		}, 2},
+		{"invitation declined", false, []step{
+			{"student", "decline", http.StatusOK, api.RequestDeclined},
+			{"student", "accept", http.StatusConflict, ""},
+		}, 1},
+		{"invitation withdrawn", false, []step{
+			{"student", "withdraw", http.StatusForbidden, ""},
+			{"owner", "withdraw", http.StatusOK, api.RequestWithdrawn},
+			{"student", "accept", http.StatusConflict, ""},
+		}, 1},
+		{"join request accepted", true, []step{
+			{"student", "accept", http.StatusForbidden, ""},
+			{"owner", "accept", http.StatusOK, api.RequestAccepted},
+		}, 2},
+		{"join request declined", true, []step{
+			{"owner", "decline", http.StatusOK, api.RequestDeclined},
+			{"owner", "accept", http.StatusConflict, ""},
+		}, 1},
+		{"join request withdrawn", true, []step{
+			{"owner", "withdraw", http.StatusForbidden, ""},
+			{"student", "withdraw", http.StatusOK, api.RequestWithdrawn},
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTeamRequestSetup(t)
+			tokens := map[string]string{"owner": s.ownerToken, "student": s.studentToken, "outsider": s.outsiderToken}
+
+			req := s.invite
+			if tt.join {
+				req = s.requestToJoin
+			}
+			created := req()
+			if created.Status != api.RequestPending {
+				t.Fatalf("new request is %s", created.Status)
+			}
+
+			for _, st := range tt.steps {
+				status, state := s.act(tokens[st.actor], created, st.action)
+				if status != st.status || state != st.state {
+					t.Errorf("%s %s: got %d %q, want %d %q", st.actor, st.action, status, state, st.status, st.state)
+				}
+			}
+
+			if got := s.memberCount(); got != tt.members {
+				t.Errorf("team has %d members, want %d", got, tt.members)
+			}
+		})
+	}
+}
+
+func TestTeamRequestConflicts(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	invitation := s.invite()
+
+	// Only one pending request per team and profile
+	rec := s.do("POST", "/api/teams/"+s.team.ID+"/join-requests", s.studentToken, api.CreateJoinRequest{})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// Unknown actions don't match a route
+	rec = s.do("POST", "/api/team-requests/"+invitation.ID+"/approve", s.studentToken, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestTeamRequestFullTeam(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	invitation := s.invite()
+
+	outsiderRec := s.do("POST", "/api/teams/"+s.team.ID+"/join-requests", s.outsiderToken, api.CreateJoinRequest{})
+	expectStatus(t, outsiderRec, http.StatusCreated)
+	var joinRequest api.TeamRequest
+	decode(t, outsiderRec, &joinRequest)
+
+	if status, _ := s.act(s.studentToken, invitation, "accept"); status != http.StatusOK {
+		t.Fatalf("accepting the invitation: %d", status)
+	}
+
+	// The team of two is full now
+	if status, _ := s.act(s.ownerToken, joinRequest, "accept"); status != http.StatusConflict {
+		t.Errorf("accepting into a full team: %d, want 409", status)
+	}
+	expectStatus(t, s.do("POST", "/api/teams/"+s.team.ID+"/invitations", s.ownerToken, api.CreateInvitationRequest{ProfileID: s.student.ID}), http.StatusConflict)
+}
+
+func TestListTeamRequests(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	invitation := s.invite()
+
+	list := func(token, direction, query string) []api.TeamRequest {
+		t.Helper()
+		rec := s.do("GET", "/api/me/team-requests/"+direction+query, token, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var requests []api.TeamRequest
+		decode(t, rec, &requests)
+		return requests
+	}
+
+	if got := list(s.studentToken, "incoming", ""); len(got) != 1 || got[0].ID != invitation.ID {
+		t.Errorf("incoming requests of the student: %+v", got)
+	}
+	if got := list(s.ownerToken, "outgoing", ""); len(got) != 1 || got[0].ID != invitation.ID {
+		t.Errorf("outgoing requests of the owner: %+v", got)
+	}
+	if got := list(s.studentToken, "outgoing", ""); len(got) != 0 {
+		t.Errorf("outgoing requests of the student: %+v", got)
+	}
+	if got := list(s.studentToken, "incoming", "?status=accepted"); len(got) != 0 {
+		t.Errorf("accepted requests: %+v", got)
+	}
+	expectStatus(t, s.do("GET", "/api/me/team-requests/incoming?status=lost", s.studentToken, nil), http.StatusBadRequest)
+}
+
+func TestTeamRequestMessage(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	path := "/api/teams/" + s.team.ID + "/join-requests"
+
+	// The limit is in characters, not bytes, and padding doesn't count
+	long := strings.Repeat("é", 500)
+	rec := s.do("POST", path, s.studentToken, api.CreateJoinRequest{Message: "  " + long + "\n"})
+	expectStatus(t, rec, http.StatusCreated)
+	var req api.TeamRequest
+	decode(t, rec, &req)
+	if req.Message != long {
+		t.Errorf("message wasn't trimmed: %q", req.Message)
+	}
+
+	rec = s.do("POST", path, s.outsiderToken, api.CreateJoinRequest{Message: long + "é"})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = s.do("POST", "/api/teams/"+s.team.ID+"/invitations", s.ownerToken, api.CreateInvitationRequest{})
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+// brokenProfileLookupDB fails looking up the profile of a user
+type brokenProfileLookupDB struct {
+	*memory.MemoryDB
+}
+
+func (db brokenProfileLookupDB) GetProfileByUserID(userID string) (api.StudentProfile, error) {
+	return api.StudentProfile{}, errors.New("connection refused")
+}
+
+func TestTeamRequestActionProfileLookupFailure(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	req := s.invite()
+
+	s.handler = api.NewAPIServer(brokenProfileLookupDB{s.db}, testSecret).Handler()
+	if status, _ := s.act(s.studentToken, req, "accept"); status != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", status, http.StatusInternalServerError)
+	}
+}
+
+func TestShrinkTeamBelowMembers(t *testing.T) {
+	s := newTeamRequestSetup(t)
+	update := testTeam()
+	update.MaxSize = 3
+	expectStatus(t, s.do("PUT", "/api/teams/"+s.team.ID, s.ownerToken, update), http.StatusOK)
+
+	for _, token := range []string{s.studentToken, s.outsiderToken} {
+		rec := s.do("POST", "/api/teams/"+s.team.ID+"/join-requests", token, api.CreateJoinRequest{})
+		expectStatus(t, rec, http.StatusCreated)
+		var req api.TeamRequest
+		decode(t, rec, &req)
+		if status, _ := s.act(s.ownerToken, req, "accept"); status != http.StatusOK {
+			t.Fatalf("accept status = %d", status)
+		}
+	}
+
+	update.MaxSize = 2
+	expectStatus(t, s.do("PUT", "/api/teams/"+s.team.ID, s.ownerToken, update), http.StatusConflict)
+	if got := s.memberCount(); got != 3 {
+		t.Errorf("member count = %d, want 3", got)
+	}
+}
@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func testTeam() api.Team {
+	return api.Team{
+		Name:           "Hackathon crew",
+		Description:    "Building a campus marketplace",
+		RequiredSkills: []string{"Go", "React"},
+		Focus:          []string{"Backend"},
+		MaxSize:        4,
+	}
+}
+
+// teamLead registers a team lead with a profile and returns their token
+func (a *testAPI) teamLead(email string) string {
+	a.t.Helper()
+	token, _ := a.registerAs(email, types.RoleTeamLead)
+	a.createProfile(token, testProfile(email))
+	return token
+}
+
+// createTeam creates a team owned by the holder of token
+func (a *testAPI) createTeam(token string, team api.Team) api.Team {
+	a.t.Helper()
+	rec := a.do("POST", "/api/teams", token, team)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var created api.Team
+	decode(a.t, rec, &created)
+	return created
+}
+
+func TestCreateTeamNormalizesSkills(t *testing.T) {
+	a := newTestAPI(t)
+	token := a.teamLead("lead@example.com")
+
+	team := testTeam()
+	team.Name = "  Hackathon crew  "
+	team.RequiredSkills = []string{"Go", " go ", "", "React"}
+	created := a.createTeam(token, team)
+
+	if created.Name != "Hackathon crew" {
+		t.Errorf("name = %q, want it trimmed", created.Name)
+	}
+	if strings.Join(created.RequiredSkills, ",") != "Go,React" {
+		t.Errorf("required_skills = %q, want [Go React]", created.RequiredSkills)
+	}
+}
+
+func TestCreateTeamValidation(t *testing.T) {
+	a := newTestAPI(t)
+	token := a.teamLead("lead@example.com")
+
+	tests := []struct {
+		name  string
+		field string
+		edit  func(*api.Team)
+	}{
+		{"blank name", "name", func(t *api.Team) { t.Name = " " }},
+		{"long name", "name", func(t *api.Team) { t.Name = strings.Repeat("a", 101) }},
+		{"long description", "description", func(t *api.Team) { t.Description = strings.Repeat("a", 2001) }},
+		{"long skill", "required_skills", func(t *api.Team) { t.RequiredSkills = []string{strings.Repeat("a", 51)} }},
+		{"too many focus areas", "focus", func(t *api.Team) {
+			t.Focus = nil
+			for i := 0; i < 11; i++ {
+				t.Focus = append(t.Focus, strings.Repeat("f", i+1))
+			}
+		}},
+		{"small max_size", "max_size", func(t *api.Team) { t.MaxSize = 1 }},
+		{"large max_size", "max_size", func(t *api.Team) { t.MaxSize = 51 }},
+		{"full status", "status", func(t *api.Team) { t.Status = api.TeamStatusFull }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team := testTeam()
+			tt.edit(&team)
+			rec := a.do("POST", "/api/teams", token, team)
+			expectStatus(t, rec, http.StatusBadRequest)
+
+			var p problem.Problem
+			decode(t, rec, &p)
+			if len(p.Errors) == 0 || p.Errors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want one for %s", p.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestTeamOwnership(t *testing.T) {
+	a := newTestAPI(t)
+	ownerToken := a.teamLead("owner@example.com")
+	team := a.createTeam(ownerToken, testTeam())
+	otherToken := a.teamLead("other@example.com")
+	moderatorToken, _ := a.registerAs("moderator@example.com", types.RoleFacultyModerator)
+
+	path := "/api/teams/" + team.ID
+	update := testTeam()
+	update.Name = "Renamed"
+
+	expectStatus(t, a.do("PUT", path, otherToken, update), http.StatusForbidden)
+	expectStatus(t, a.do("DELETE", path, otherToken, nil), http.StatusForbidden)
+	expectStatus(t, a.do("POST", path+"/invitations", otherToken, api.CreateInvitationRequest{ProfileID: team.ID}), http.StatusForbidden)
+
+	expectStatus(t, a.do("PUT", path, ownerToken, update), http.StatusOK)
+	expectStatus(t, a.do("DELETE", path, moderatorToken, nil), http.StatusNoContent)
+}
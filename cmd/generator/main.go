@@ -28,21 +28,9 @@ var (
 		"Medicine":        {"General Medicine", "Dentistry", "Pharmacy", "Nursing", "Public Health"},
 	}
 
-	skills = []string{
-		"Python", "Java", "Go", "JavaScript", "C++",
-		"React", "Node.js", "Docker", "Kubernetes", "AWS",
-		"Data Analysis", "Machine Learning", "UI/UX Design",
-		"Project Management", "Team Leadership", "Communication",
-		"Problem Solving", "Critical Thinking", "Research",
-		"Public Speaking", "Technical Writing",
-	}
-
-	focus = []string{
-		"Web Development", "Mobile Development", "Cloud Computing",
-		"Data Science", "Artificial Intelligence", "Cybersecurity",
-		"DevOps", "Systems Design", "UI/UX", "Product Management",
-		"Research", "Innovation", "Entrepreneurship",
-	}
+	// skills and focus come from the taxonomy, see loadVocabulary
+	skills []string
+	focus  []string
 )
 
 func generateRandomProfile(id int) *api.StudentProfile {
@@ -71,6 +59,15 @@ func generateRandomProfile(id int) *api.StudentProfile {
 		}
 	}
 
+	skillLevels := make([]api.SkillLevel, 0, len(selectedSkills))
+	for _, skill := range selectedSkills {
+		skillLevels = append(skillLevels, api.SkillLevel{
+			Skill: skill,
+			Level: api.ProficiencyLevels[rand.Intn(len(api.ProficiencyLevels))],
+			Years: rand.Intn(5),
+		})
+	}
+
 	return &api.StudentProfile{
 		Name:         fmt.Sprintf("Student %d", id),
 		Email:        fmt.Sprintf("student%d@university.edu", id),
@@ -79,10 +76,35 @@ func generateRandomProfile(id int) *api.StudentProfile {
 		Semester:     rand.Intn(8) + 1, 
 		Skills:       selectedSkills,
 		Focus:        selectedFocus,
+		SkillLevels:  skillLevels,
 		IsAvailable:  rand.Float32() > 0.3, 
 	}
 }
 
+// loadVocabulary picks skills and focus areas from the canonical taxonomy so
+// that generated profiles use the same names as real ones
+func loadVocabulary(db *postgres.PostgresDB) error {
+	for _, v := range []struct {
+		kind  string
+		names *[]string
+	}{
+		{api.TermSkill, &skills},
+		{api.TermFocus, &focus},
+	} {
+		terms, err := db.SearchTerms(v.kind, "", 0)
+		if err != nil {
+			return err
+		}
+		if len(terms) == 0 {
+			return fmt.Errorf("the taxonomy has no %s entries, run the migrations first", v.kind)
+		}
+		for _, term := range terms {
+			*v.names = append(*v.names, term.Name)
+		}
+	}
+	return nil
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -100,6 +122,10 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	if err := loadVocabulary(db); err != nil {
+		log.Fatalf("Failed to load skills and focus areas: %v", err)
+	}
+
 	for i := 1; i <= 10000; i++ {
 		profile := generateRandomProfile(i)
 		
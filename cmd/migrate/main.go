@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/cmd/migrate/migrations"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/config"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/database/migrate"
+)
+
+const usage = `Usage: migrate [--config FILE] [--db-conn-string DSN] <command>
+
+Commands:
+  up            apply all pending migrations
+  down [N]      revert the last N migrations (default 1)
+  to VERSION    migrate up or down to VERSION (0 reverts everything)
+  status        list migrations and whether they are applied
+
+The connection string comes from --db-conn-string, DB_CONN_STRING or the
+db_conn_string of the configuration file, like for the server.
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+
+	// The environment may already be set, e.g. in CI
+	godotenv.Load()
+
+	dbConnString, err := config.LoadDBConnString(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := sql.Open("postgres", dbConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	args := flag.Args()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				log.Fatalf("down expects a positive number of migrations, got %q", args[1])
+			}
+		}
+		err = migrator.Down(ctx, n)
+	case "to":
+		if len(args) < 2 {
+			log.Fatal("to expects a version")
+		}
+		version, perr := strconv.ParseInt(args[1], 10, 64)
+		if perr != nil || version < 0 {
+			log.Fatalf("invalid version %q", args[1])
+		}
+		err = migrator.To(ctx, version)
+	case "status":
+		err = printStatus(ctx, migrator)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if args[0] != "status" {
+		current, err := migrator.Current(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Schema is at version %d (latest %d)", current, migrator.Latest())
+	}
+}
+
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%06d  %-40s %s\n", s.Version, s.Name, state)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+-- IF NOT EXISTS lets databases created from the old README setup adopt the migrations
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS student_profiles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) NOT NULL UNIQUE,
+    faculty VARCHAR(100) NOT NULL,
+    field_of_study VARCHAR(100) NOT NULL,
+    semester INTEGER NOT NULL,
+    skills TEXT[] NOT NULL,
+    focus TEXT[] NOT NULL,
+    is_available BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
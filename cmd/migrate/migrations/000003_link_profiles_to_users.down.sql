@@ -0,0 +1 @@
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,2 @@
+ALTER TABLE student_profiles
+    ADD COLUMN IF NOT EXISTS user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS student_profiles_created_at_idx;
+DROP INDEX IF EXISTS student_profiles_name_idx;
+DROP INDEX IF EXISTS student_profiles_semester_idx;
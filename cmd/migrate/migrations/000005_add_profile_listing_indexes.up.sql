@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS student_profiles_created_at_idx ON student_profiles (created_at, id);
+CREATE INDEX IF NOT EXISTS student_profiles_name_idx ON student_profiles (name, id);
+CREATE INDEX IF NOT EXISTS student_profiles_semester_idx ON student_profiles (semester, id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    required_skills TEXT[] NOT NULL DEFAULT '{}',
+    focus TEXT[] NOT NULL DEFAULT '{}',
+    max_size INTEGER NOT NULL CHECK (max_size > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS teams_required_skills_idx ON teams USING GIN (required_skills);
+CREATE INDEX IF NOT EXISTS teams_created_at_idx ON teams (created_at, id);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    profile_id UUID NOT NULL REFERENCES student_profiles(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, profile_id)
+);
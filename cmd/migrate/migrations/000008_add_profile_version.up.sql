@@ -0,0 +1 @@
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS taxonomy_aliases;
+DROP TABLE IF EXISTS taxonomy_terms;
@@ -0,0 +1,91 @@
+CREATE TABLE IF NOT EXISTS taxonomy_terms (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('skill', 'focus')),
+    name VARCHAR(50) NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS taxonomy_terms_kind_name_key ON taxonomy_terms (kind, lower(name));
+CREATE INDEX IF NOT EXISTS taxonomy_terms_name_prefix_idx ON taxonomy_terms (kind, lower(name) text_pattern_ops);
+
+-- Aliases are other spellings of a term ("golang"), synonyms other words for
+-- it ("Leadership"). Both are rewritten to the term's name.
+CREATE TABLE IF NOT EXISTS taxonomy_aliases (
+    term_id UUID NOT NULL REFERENCES taxonomy_terms(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL,
+    alias VARCHAR(50) NOT NULL,
+    relation VARCHAR(10) NOT NULL DEFAULT 'alias' CHECK (relation IN ('alias', 'synonym'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS taxonomy_aliases_kind_alias_key ON taxonomy_aliases (kind, lower(alias));
+CREATE INDEX IF NOT EXISTS taxonomy_aliases_prefix_idx ON taxonomy_aliases (kind, lower(alias) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS taxonomy_aliases_term_id_idx ON taxonomy_aliases (term_id);
+
+-- Start with the vocabulary the data generator used to hard-code
+INSERT INTO taxonomy_terms (id, kind, name, category)
+SELECT md5(kind || ':' || lower(name))::uuid, kind, name, category
+FROM (VALUES
+    ('skill', 'Python', 'Programming Language'),
+    ('skill', 'Java', 'Programming Language'),
+    ('skill', 'Go', 'Programming Language'),
+    ('skill', 'JavaScript', 'Programming Language'),
+    ('skill', 'C++', 'Programming Language'),
+    ('skill', 'React', 'Framework'),
+    ('skill', 'Node.js', 'Framework'),
+    ('skill', 'Docker', 'Cloud & DevOps'),
+    ('skill', 'Kubernetes', 'Cloud & DevOps'),
+    ('skill', 'AWS', 'Cloud & DevOps'),
+    ('skill', 'Data Analysis', 'Data'),
+    ('skill', 'Machine Learning', 'Data'),
+    ('skill', 'UI/UX Design', 'Design'),
+    ('skill', 'Project Management', 'Management'),
+    ('skill', 'Team Leadership', 'Management'),
+    ('skill', 'Communication', 'Soft Skill'),
+    ('skill', 'Problem Solving', 'Soft Skill'),
+    ('skill', 'Critical Thinking', 'Soft Skill'),
+    ('skill', 'Public Speaking', 'Soft Skill'),
+    ('skill', 'Research', 'Research'),
+    ('skill', 'Technical Writing', 'Research'),
+    ('focus', 'Web Development', 'Engineering'),
+    ('focus', 'Mobile Development', 'Engineering'),
+    ('focus', 'Cloud Computing', 'Engineering'),
+    ('focus', 'DevOps', 'Engineering'),
+    ('focus', 'Systems Design', 'Engineering'),
+    ('focus', 'Cybersecurity', 'Engineering'),
+    ('focus', 'Data Science', 'Data & AI'),
+    ('focus', 'Artificial Intelligence', 'Data & AI'),
+    ('focus', 'UI/UX', 'Design'),
+    ('focus', 'Product Management', 'Business'),
+    ('focus', 'Innovation', 'Business'),
+    ('focus', 'Entrepreneurship', 'Business'),
+    ('focus', 'Research', 'Academia')
+) AS seed (kind, name, category)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO taxonomy_aliases (term_id, kind, alias, relation)
+SELECT t.id, t.kind, seed.alias, seed.relation
+FROM (VALUES
+    ('skill', 'Go', 'Golang', 'alias'),
+    ('skill', 'JavaScript', 'JS', 'alias'),
+    ('skill', 'JavaScript', 'ECMAScript', 'alias'),
+    ('skill', 'C++', 'CPP', 'alias'),
+    ('skill', 'React', 'React.js', 'alias'),
+    ('skill', 'React', 'ReactJS', 'alias'),
+    ('skill', 'Node.js', 'Node', 'alias'),
+    ('skill', 'Node.js', 'NodeJS', 'alias'),
+    ('skill', 'Kubernetes', 'K8s', 'alias'),
+    ('skill', 'AWS', 'Amazon Web Services', 'alias'),
+    ('skill', 'Machine Learning', 'ML', 'alias'),
+    ('skill', 'UI/UX Design', 'UX Design', 'synonym'),
+    ('skill', 'UI/UX Design', 'UI Design', 'synonym'),
+    ('skill', 'Data Analysis', 'Data Analytics', 'synonym'),
+    ('skill', 'Team Leadership', 'Leadership', 'synonym'),
+    ('skill', 'Public Speaking', 'Presenting', 'synonym'),
+    ('focus', 'Artificial Intelligence', 'AI', 'alias'),
+    ('focus', 'Cybersecurity', 'Information Security', 'synonym'),
+    ('focus', 'Cybersecurity', 'InfoSec', 'alias'),
+    ('focus', 'Mobile Development', 'App Development', 'synonym'),
+    ('focus', 'UI/UX', 'User Experience', 'synonym')
+) AS seed (kind, name, alias, relation)
+JOIN taxonomy_terms t ON t.kind = seed.kind AND t.name = seed.name
+ON CONFLICT DO NOTHING;
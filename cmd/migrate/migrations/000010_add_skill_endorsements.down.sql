@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS skill_endorsements;
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS skill_levels;
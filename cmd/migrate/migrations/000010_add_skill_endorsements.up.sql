@@ -0,0 +1,14 @@
+-- Self-assessed proficiency per skill: [{"skill": "Go", "level": "advanced", "years": 2}]
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS skill_levels JSONB NOT NULL DEFAULT '[]';
+
+-- One endorsement per user and skill. Endorsements of skills that are later
+-- removed from the profile are kept but not counted, so they come back if the
+-- skill is added again.
+CREATE TABLE IF NOT EXISTS skill_endorsements (
+    profile_id UUID NOT NULL REFERENCES student_profiles(id) ON DELETE CASCADE,
+    skill VARCHAR(50) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (profile_id, skill, user_id)
+);
+CREATE INDEX IF NOT EXISTS skill_endorsements_user_id_idx ON skill_endorsements (user_id);
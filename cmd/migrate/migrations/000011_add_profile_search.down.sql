@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS student_profiles_search_update ON student_profiles;
+DROP FUNCTION IF EXISTS student_profiles_search_update();
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS search_text;
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS bio;
@@ -0,0 +1,33 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '';
+
+-- search_vector backs full-text search, search_text the trigram similarity
+-- used to tolerate typos. Both are kept up to date by a trigger.
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+CREATE OR REPLACE FUNCTION student_profiles_search_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', array_to_string(NEW.skills, ' ')), 'A') ||
+        setweight(to_tsvector('english', array_to_string(NEW.focus, ' ')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.field_of_study, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.bio, '')), 'C');
+    NEW.search_text := lower(concat_ws(' ',
+        NEW.name, NEW.field_of_study, array_to_string(NEW.skills, ' '), array_to_string(NEW.focus, ' '), NEW.bio));
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS student_profiles_search_update ON student_profiles;
+CREATE TRIGGER student_profiles_search_update
+    BEFORE INSERT OR UPDATE OF name, field_of_study, skills, focus, bio ON student_profiles
+    FOR EACH ROW EXECUTE FUNCTION student_profiles_search_update();
+
+-- Fill both columns for existing profiles
+UPDATE student_profiles SET bio = bio;
+
+CREATE INDEX IF NOT EXISTS student_profiles_search_vector_idx ON student_profiles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS student_profiles_search_text_idx ON student_profiles USING GIN (search_text gin_trgm_ops);
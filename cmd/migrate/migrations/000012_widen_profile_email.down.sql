@@ -0,0 +1,2 @@
+-- Fails if a profile has an email longer than 100 characters
+ALTER TABLE student_profiles ALTER COLUMN email TYPE VARCHAR(100);
@@ -0,0 +1,2 @@
+-- Profiles accept emails up to 254 characters like users do (RFC 5321)
+ALTER TABLE student_profiles ALTER COLUMN email TYPE VARCHAR(254);
@@ -0,0 +1 @@
+ALTER TABLE student_profiles ALTER COLUMN is_available DROP NOT NULL;
@@ -0,0 +1,3 @@
+-- A NULL availability fails to scan and is counted as unavailable when matching
+UPDATE student_profiles SET is_available = false WHERE is_available IS NULL;
+ALTER TABLE student_profiles ALTER COLUMN is_available SET NOT NULL;
@@ -1,33 +1,162 @@
 package main
 
 import (
+    "context"
+    "crypto/tls"
+    "errors"
+    "flag"
+    "io/fs"
     "log"
+    "os"
+    "os/signal"
+    "syscall"
+
     "github.com/rizkyswandy/TeamSeekerBackend/api"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/certs"
+    "github.com/rizkyswandy/TeamSeekerBackend/cmd/migrate/migrations"
     "github.com/rizkyswandy/TeamSeekerBackend/internal/config"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/database/memory"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/database/migrate"
     "github.com/rizkyswandy/TeamSeekerBackend/internal/database/postgres"
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
     "github.com/joho/godotenv"
 )
 
+var logger = logging.New("main")
+
 func main() {
-    err := godotenv.Load()
-    if err != nil {
+    printConfig := flag.Bool("print-config", false, "print the effective configuration and exit")
+
+    // .env is optional, the environment may already be set, e.g. by systemd.
+    // It doesn't override variables that are.
+    if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
         log.Fatalf("Error loading .env file: %v", err)
     }
-    
-    cfg := config.LoadConfig()
 
-    db, err := postgres.NewPostgresDB(cfg.DBConnString)
+    cfg, err := config.Load(flag.CommandLine, os.Args[1:])
     if err != nil {
+        log.Fatalf("Invalid configuration:\n%v", err)
+    }
+    if *printConfig {
+        cfg.Print(os.Stdout)
+        return
+    }
+
+    // Standard output is left to --print-config
+    if err := logging.Configure(os.Stderr, cfg.LogFormat, cfg.LogLevels); err != nil {
         log.Fatal(err)
     }
+    logger.Info("Loaded configuration", "config", cfg)
+
+    var db api.Database
+    switch cfg.Store {
+    case "postgres":
+        pg, err := postgres.NewPostgresDB(cfg.DBConnString)
+        if err != nil {
+            fatal("Failed to connect to the database", err)
+        }
+        checkSchema(pg, cfg.RequireSchemaCurrent)
+        db = pg
+    case "memory":
+        logger.Warn("Using in-memory store, all data is lost on restart")
+        db = memory.NewMemoryDB()
+    }
 
     server := api.NewAPIServer(db, cfg.JWTSecret)
+    if err := server.BootstrapAdmins(cfg.AdminEmails); err != nil {
+        fatal("Failed to promote the admins of ADMIN_EMAILS", err)
+    }
 
     //Note: Keep for development
     // cfg.ServerPort = "3001"
 
-    log.Printf("Server starting on port %s", cfg.ServerPort)
-    if err := server.Start("0.0.0.0:" + cfg.ServerPort); err != nil {
-        log.Fatal(err)
+    // SIGINT or SIGTERM (e.g. systemctl stop during a deploy) starts a graceful
+    // shutdown. A second signal kills the process right away.
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    go func() {
+        <-ctx.Done()
+        stop()
+    }()
+
+    tlsConfig, adminTLS := loadTLS(ctx, cfg)
+
+    err = server.Start(ctx, api.ServerOptions{
+        Addrs:             cfg.ListenAddrs,
+        TLS:               tlsConfig,
+        AdminAddrs:        cfg.AdminListenAddrs,
+        AdminTLS:          adminTLS,
+        SocketMode:        cfg.SocketMode,
+        ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+        ReadTimeout:       cfg.ReadTimeout,
+        WriteTimeout:      cfg.WriteTimeout,
+        IdleTimeout:       cfg.IdleTimeout,
+        MaxHeaderBytes:    cfg.MaxHeaderBytes,
+        ShutdownTimeout:   cfg.ShutdownTimeout,
+    })
+    stop()
+
+    if closeErr := db.Close(); closeErr != nil {
+        logger.Error("Failed to close the database", "error", closeErr)
+    }
+    if err != nil {
+        fatal("Server failed", err)
+    }
+}
+
+// fatal logs err and exits, like log.Fatal
+func fatal(msg string, err error) {
+    logger.Error(msg, "error", err)
+    os.Exit(1)
+}
+
+// loadTLS builds the TLS configurations of the public and admin listeners,
+// or returns nil ones when TLS isn't configured. The certificate is reloaded
+// until ctx is done.
+func loadTLS(ctx context.Context, cfg *config.Config) (*tls.Config, *tls.Config) {
+    if cfg.TLSCertFile == "" {
+        return nil, nil
+    }
+
+    reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+    if err != nil {
+        fatal("Failed to load the TLS certificate", err)
+    }
+    go reloader.Watch(ctx, cfg.CertReloadInterval)
+
+    tlsConfig, err := certs.ServerConfig(reloader, cfg.TLSMinVersion, cfg.TLSCipherSuites)
+    if err != nil {
+        fatal("Invalid TLS configuration", err)
+    }
+
+    adminTLS := tlsConfig
+    if cfg.TLSClientCAFile != "" {
+        adminTLS, err = certs.RequireClientCerts(tlsConfig, cfg.TLSClientCAFile)
+        if err != nil {
+            fatal("Failed to load the client CAs", err)
+        }
+    }
+
+    return tlsConfig, adminTLS
+}
+
+// checkSchema warns when the database is behind the migrations embedded in
+// the binary, or refuses to start if required is set
+func checkSchema(db *postgres.PostgresDB, required bool) {
+    migrator, err := migrate.New(db.DB(), migrations.FS)
+    if err != nil {
+        fatal("Failed to load migrations", err)
+    }
+
+    current, err := migrator.Current(context.Background())
+    if err != nil {
+        fatal("Failed to read schema version", err)
+    }
+
+    if current < migrator.Latest() {
+        if required {
+            logger.Error("Database schema is behind, run `make migrate-up`", "version", current, "required", migrator.Latest())
+            os.Exit(1)
+        }
+        logger.Warn("Database schema is behind, run `make migrate-up`", "version", current, "latest", migrator.Latest())
     }
-}
\ No newline at end of file
+}
@@ -3,6 +3,7 @@ module github.com/rizkyswandy/TeamSeekerBackend
 go 1.23.3
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
@@ -0,0 +1,100 @@
+// Package apperr defines the kinds of domain errors shared by the storage
+// implementations and the API. Callers check the kind with errors.Is, e.g.
+// errors.Is(err, apperr.ErrNotFound), and the API maps kinds to status codes.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrForbidden    = errors.New("forbidden")
+	ErrTooLarge     = errors.New("too large")
+	// ErrPreconditionFailed means the resource changed since the client read it
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a domain error of a given kind. Message is safe to show to clients.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func InvalidInput(format string, args ...interface{}) error {
+	return &Error{Kind: ErrInvalidInput, Message: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func TooLarge(format string, args ...interface{}) error {
+	return &Error{Kind: ErrTooLarge, Message: fmt.Sprintf(format, args...)}
+}
+
+func PreconditionFailed(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+// FieldError is one rejected field of a request
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError collects the invalid fields of a request so that they can
+// be reported together. It is an ErrInvalidInput.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Message
+	}
+	return fmt.Sprintf("%d fields are invalid", len(e.Fields))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// Add records a problem with field
+func (e *ValidationError) Add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e if any field was added and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Invalid returns a validation error for a single field
+func Invalid(field, format string, args ...interface{}) error {
+	e := &ValidationError{}
+	e.Add(field, format, args...)
+	return e
+}
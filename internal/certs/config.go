@@ -0,0 +1,81 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// ServerConfig returns the TLS configuration of a server presenting the
+// certificate of r. minVersion is "1.2" or "1.3"; cipherSuites are names
+// as listed by tls.CipherSuites and only apply to TLS 1.2, an empty list
+// uses Go's defaults.
+func ServerConfig(r *Reloader, minVersion string, cipherSuites []string) (*tls.Config, error) {
+	version, err := parseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := parseCipherSuites(cipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     version,
+		CipherSuites:   suites,
+	}, nil
+}
+
+// RequireClientCerts returns a copy of config that only accepts clients
+// presenting a certificate signed by one of the CAs in caFile
+func RequireClientCerts(config *tls.Config, caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	config = config.Clone()
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.ClientCAs = pool
+	return config, nil
+}
+
+func parseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimum TLS version %q, use 1.2 or 1.3", version)
+	}
+}
+
+// parseCipherSuites only accepts the suites Go considers secure
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -1,37 +1,345 @@
+// Package config loads the server settings. Each setting can come from, in
+// increasing order of precedence, its default, a TOML file, the environment
+// and a command-line flag.
 package config
 
 import (
-    "log"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "log/slog"
     "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
 )
 
+// MinJWTSecretLength is the shortest JWT_SECRET accepted, the size of the
+// HMAC-SHA256 key that signs the tokens
+const MinJWTSecretLength = 32
+
 type Config struct {
+    // Store is the storage backend, postgres or memory
+    Store        string
     DBConnString string
     JWTSecret    []byte
     ServerPort   string
+    // ListenAddrs are the addresses the server listens on, see package
+    // listen. The default is every interface, IPv4 and IPv6, on ServerPort.
+    ListenAddrs []string
+    // SocketMode is the file mode of Unix sockets in ListenAddrs
+    SocketMode os.FileMode
+    // RequireSchemaCurrent makes the server refuse to start when migrations are pending
+    RequireSchemaCurrent bool
+
+    // Limits of the HTTP server, see http.Server
+    ReadHeaderTimeout time.Duration
+    ReadTimeout       time.Duration
+    WriteTimeout      time.Duration
+    IdleTimeout       time.Duration
+    MaxHeaderBytes    int
+    // ShutdownTimeout is how long in-flight requests get to finish after
+    // SIGINT or SIGTERM
+    ShutdownTimeout time.Duration
+
+    // TLSCertFile and TLSKeyFile make the server speak HTTPS. They are
+    // reloaded on SIGHUP and checked for changes every CertReloadInterval.
+    TLSCertFile        string
+    TLSKeyFile         string
+    TLSMinVersion      string
+    TLSCipherSuites    []string
+    CertReloadInterval time.Duration
+    // AdminListenAddrs serve the /api/admin routes instead of ListenAddrs.
+    // With TLSClientCAFile they require a client certificate signed by it.
+    AdminListenAddrs []string
+    TLSClientCAFile  string
+
+    // AdminEmails are the accounts made admins at startup and registration
+    AdminEmails []string
+
+    // LogFormat is json or text. LogLevels are the minimum levels of the
+    // loggers of each package.
+    LogFormat string
+    LogLevels logging.Levels
+
+    // values are the merged settings and where each came from, for Print
+    values values
 }
 
-func LoadConfig() *Config {
-    jwtSecret := os.Getenv("JWT_SECRET")
-    if jwtSecret == "" {
-        log.Fatal("JWT_SECRET environment variable is required")
+// Load registers a flag for every setting on flags, parses args with it and
+// merges the result with the defaults, the file named by --config or
+// CONFIG_FILE and the environment. All invalid settings are reported at once.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+    v, err := merge(flags, args)
+    if err != nil {
+        return nil, err
     }
+    return v.config()
+}
 
-    dbConnString := os.Getenv("DB_CONN_STRING")
+// LoadDBConnString is Load for tools which only need the database, like
+// cmd/migrate. It reads the same flags, file and environment but doesn't
+// require the other settings to be valid.
+func LoadDBConnString(flags *flag.FlagSet, args []string) (string, error) {
+    v, err := merge(flags, args)
+    if err != nil {
+        return "", err
+    }
+
+    p := &parser{values: v}
+    dbConnString := p.string("DB_CONN_STRING")
     if dbConnString == "" {
-        dbConnString = "postgres://pang1@localhost:5432/team_seeker?sslmode=disable"
-        log.Println("Warning: Using default database connection. Set DB_CONN_STRING environment variable in production.")
+        return "", errors.New("DB_CONN_STRING is required")
+    }
+    return dbConnString, nil
+}
+
+// merge parses args and layers the settings from every source
+func merge(flags *flag.FlagSet, args []string) (values, error) {
+    configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "TOML configuration file")
+    for _, s := range settings {
+        flags.Var(&flagValue{value: s.def, isBool: s.isBool}, s.flagName(), s.usage)
+    }
+    if err := flags.Parse(args); err != nil {
+        return nil, err
+    }
+
+    v := values{}
+    for _, s := range settings {
+        v[s.name] = value{raw: s.def, source: "default"}
+    }
+
+    if *configFile != "" {
+        file, err := parseFile(*configFile)
+        if err != nil {
+            return nil, err
+        }
+        for key, fv := range file {
+            s, ok := settingByKey(key)
+            if !ok {
+                return nil, fmt.Errorf("%s: unknown setting %q", *configFile, key)
+            }
+            v[s.name] = value{raw: fileValue(s, fv), source: *configFile}
+        }
+    }
+
+    for _, s := range settings {
+        if raw := os.Getenv(s.name); raw != "" {
+            v[s.name] = value{raw: raw, source: "environment"}
+        }
+    }
+
+    flags.Visit(func(f *flag.Flag) {
+        if s, ok := settingByFlag(f.Name); ok {
+            v[s.name] = value{raw: f.Value.String(), source: "flag"}
+        }
+    })
+
+    if v["LISTEN_ADDRS"].raw == "" {
+        v["LISTEN_ADDRS"] = value{raw: ":" + v["SERVER_PORT"].raw, source: "default"}
+    }
+
+    return v, nil
+}
+
+// config converts the merged settings, collecting every problem
+func (v values) config() (*Config, error) {
+    p := &parser{values: v}
+    cfg := &Config{
+        Store:                p.string("STORE"),
+        DBConnString:         p.string("DB_CONN_STRING"),
+        JWTSecret:            []byte(p.string("JWT_SECRET")),
+        ServerPort:           p.string("SERVER_PORT"),
+        ListenAddrs:          p.list("LISTEN_ADDRS"),
+        SocketMode:           p.fileMode("SOCKET_MODE"),
+        RequireSchemaCurrent: p.bool("REQUIRE_SCHEMA_CURRENT"),
+
+        ReadHeaderTimeout: p.duration("READ_HEADER_TIMEOUT"),
+        ReadTimeout:       p.duration("READ_TIMEOUT"),
+        WriteTimeout:      p.duration("WRITE_TIMEOUT"),
+        IdleTimeout:       p.duration("IDLE_TIMEOUT"),
+        MaxHeaderBytes:    p.int("MAX_HEADER_BYTES"),
+        ShutdownTimeout:   p.duration("SHUTDOWN_TIMEOUT"),
+
+        TLSCertFile:        p.string("TLS_CERT_FILE"),
+        TLSKeyFile:         p.string("TLS_KEY_FILE"),
+        TLSMinVersion:      p.string("TLS_MIN_VERSION"),
+        TLSCipherSuites:    p.list("TLS_CIPHER_SUITES"),
+        CertReloadInterval: p.duration("CERT_RELOAD_INTERVAL"),
+        AdminListenAddrs:   p.list("ADMIN_LISTEN_ADDRS"),
+        TLSClientCAFile:    p.string("TLS_CLIENT_CA_FILE"),
+
+        AdminEmails: p.list("ADMIN_EMAILS"),
+
+        LogFormat: p.string("LOG_FORMAT"),
+        LogLevels: p.logLevels("LOG_LEVEL"),
+
+        values: v,
+    }
+
+    errs := append(p.errs, cfg.validate()...)
+    if len(errs) > 0 {
+        return nil, errors.Join(errs...)
+    }
+    return cfg, nil
+}
+
+// validate checks the settings that depend on each other or have limits
+// beyond their type
+func (c *Config) validate() []error {
+    var errs []error
+    invalid := func(format string, args ...interface{}) {
+        errs = append(errs, fmt.Errorf(format, args...))
+    }
+
+    switch c.Store {
+    case "postgres":
+        if c.DBConnString == "" {
+            invalid("DB_CONN_STRING is required with the postgres store")
+        }
+    case "memory":
+    default:
+        invalid("STORE must be postgres or memory, got %q", c.Store)
     }
 
-    serverPort := os.Getenv("SERVER_PORT")
-    if serverPort == "" {
-        serverPort = "3000"
-        log.Printf("Using default port 3000")
+    if len(c.JWTSecret) == 0 {
+        invalid("JWT_SECRET is required")
+    } else if len(c.JWTSecret) < MinJWTSecretLength {
+        invalid("JWT_SECRET must be at least %d bytes, got %d", MinJWTSecretLength, len(c.JWTSecret))
     }
 
-    return &Config{
-        DBConnString: dbConnString,
-        JWTSecret:    []byte(jwtSecret),
-        ServerPort:   serverPort,
+    if port, err := strconv.Atoi(c.ServerPort); err != nil || port < 1 || port > 65535 {
+        invalid("SERVER_PORT must be a port number, got %q", c.ServerPort)
+    }
+    if len(c.ListenAddrs) == 0 {
+        invalid("LISTEN_ADDRS must list at least one address")
+    }
+
+    if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+        invalid("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+    }
+    if c.TLSMinVersion != "1.2" && c.TLSMinVersion != "1.3" {
+        invalid("TLS_MIN_VERSION must be 1.2 or 1.3, got %q", c.TLSMinVersion)
+    }
+    if c.TLSClientCAFile != "" && (c.TLSCertFile == "" || len(c.AdminListenAddrs) == 0) {
+        invalid("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and ADMIN_LISTEN_ADDRS")
     }
-}
\ No newline at end of file
+
+    if c.LogFormat != "json" && c.LogFormat != "text" {
+        invalid("LOG_FORMAT must be json or text, got %q", c.LogFormat)
+    }
+
+    return errs
+}
+
+// Print writes the effective configuration and where each setting came
+// from, with secrets redacted
+func (c *Config) Print(w io.Writer) {
+    for _, s := range settings {
+        v := c.values[s.name]
+        fmt.Fprintf(w, "%-24s %-40s (%s)\n", s.name, strconv.Quote(c.display(s)), v.source)
+    }
+}
+
+// LogValue logs the effective configuration with secrets redacted
+func (c *Config) LogValue() slog.Value {
+    attrs := make([]slog.Attr, len(settings))
+    for i, s := range settings {
+        attrs[i] = slog.String(s.key(), c.display(s))
+    }
+    return slog.GroupValue(attrs...)
+}
+
+// display is the value of s as it may be shown
+func (c *Config) display(s setting) string {
+    raw := c.values[s.name].raw
+    if s.redact != nil && raw != "" {
+        return s.redact(raw)
+    }
+    return raw
+}
+
+// flagValue is the flag of a setting. Flags of boolean settings can be
+// given without a value.
+type flagValue struct {
+    value  string
+    isBool bool
+}
+
+func (f *flagValue) String() string { return f.value }
+
+func (f *flagValue) Set(value string) error {
+    f.value = value
+    return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+// parser converts settings to their types, collecting the errors
+type parser struct {
+    values values
+    errs   []error
+}
+
+func (p *parser) invalid(name string, expected string) {
+    p.errs = append(p.errs, fmt.Errorf("%s must be %s, got %q (from %s)", name, expected, p.values[name].raw, p.values[name].source))
+}
+
+func (p *parser) string(name string) string {
+    return strings.TrimSpace(p.values[name].raw)
+}
+
+// list splits a comma-separated setting
+func (p *parser) list(name string) []string {
+    var list []string
+    for _, item := range strings.Split(p.values[name].raw, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            list = append(list, item)
+        }
+    }
+    return list
+}
+
+func (p *parser) bool(name string) bool {
+    b, err := strconv.ParseBool(p.string(name))
+    if err != nil {
+        p.invalid(name, "true or false")
+    }
+    return b
+}
+
+func (p *parser) int(name string) int {
+    n, err := strconv.ParseInt(p.string(name), 0, 0)
+    if err != nil || n <= 0 {
+        p.invalid(name, "a positive integer")
+    }
+    return int(n)
+}
+
+// duration reads a duration like "30s" or "2m"
+func (p *parser) duration(name string) time.Duration {
+    d, err := time.ParseDuration(p.string(name))
+    if err != nil || d < 0 {
+        p.invalid(name, "a non-negative duration like 30s")
+    }
+    return d
+}
+
+func (p *parser) logLevels(name string) logging.Levels {
+    levels, err := logging.ParseLevels(p.string(name))
+    if err != nil {
+        p.invalid(name, "a level like info, optionally followed by package=level pairs")
+    }
+    return levels
+}
+
+func (p *parser) fileMode(name string) os.FileMode {
+    mode, err := strconv.ParseUint(strings.TrimPrefix(p.string(name), "0o"), 8, 32)
+    if err != nil || mode > 0o777 {
+        p.invalid(name, "an octal file mode like 0660")
+    }
+    return os.FileMode(mode)
+}
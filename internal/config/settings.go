@@ -0,0 +1,99 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// setting is a configuration setting. Its name is the environment variable;
+// in the configuration file it is lower case, and as a flag also with
+// dashes instead of underscores.
+type setting struct {
+	name  string
+	def   string
+	usage string
+	// isBool settings can be given as a flag without a value
+	isBool bool
+	// octal settings are written in octal, also when a file gives an integer
+	octal bool
+	// redact hides secrets when printing the configuration
+	redact func(string) string
+}
+
+// settings are all settings, in the order they are printed
+var settings = []setting{
+	{name: "STORE", def: "postgres", usage: "storage backend: postgres or memory (non-persistent, for local development)"},
+	{name: "DB_CONN_STRING", usage: "PostgreSQL connection string", redact: redactConnString},
+	{name: "JWT_SECRET", usage: "secret that signs the access tokens, at least 32 bytes", redact: redactAll},
+	{name: "SERVER_PORT", def: "3000", usage: "port of the default listen address"},
+	{name: "LISTEN_ADDRS", usage: "comma-separated addresses to listen on (default :SERVER_PORT)"},
+	{name: "SOCKET_MODE", def: "0660", usage: "file mode of Unix sockets", octal: true},
+	{name: "REQUIRE_SCHEMA_CURRENT", def: "false", usage: "refuse to start while migrations are pending", isBool: true},
+	{name: "READ_HEADER_TIMEOUT", def: "5s", usage: "time to read the request headers"},
+	{name: "READ_TIMEOUT", def: "15s", usage: "time to read the whole request"},
+	{name: "WRITE_TIMEOUT", def: "30s", usage: "time to write the response"},
+	{name: "IDLE_TIMEOUT", def: "2m", usage: "how long keep-alive connections stay open"},
+	{name: "MAX_HEADER_BYTES", def: "1048576", usage: "maximum size of the request headers"},
+	{name: "SHUTDOWN_TIMEOUT", def: "30s", usage: "how long in-flight requests get to finish on shutdown"},
+	{name: "TLS_CERT_FILE", usage: "PEM certificate, serves HTTPS when set"},
+	{name: "TLS_KEY_FILE", usage: "PEM private key of the certificate"},
+	{name: "TLS_MIN_VERSION", def: "1.2", usage: "minimum TLS version: 1.2 or 1.3"},
+	{name: "TLS_CIPHER_SUITES", usage: "comma-separated TLS 1.2 cipher suites (default Go's)"},
+	{name: "CERT_RELOAD_INTERVAL", def: "1m", usage: "how often to check the certificate files for changes, 0 to only reload on SIGHUP"},
+	{name: "ADMIN_LISTEN_ADDRS", usage: "comma-separated addresses that serve /api/admin instead of LISTEN_ADDRS"},
+	{name: "TLS_CLIENT_CA_FILE", usage: "CA bundle that client certificates on ADMIN_LISTEN_ADDRS must be signed by"},
+	{name: "ADMIN_EMAILS", usage: "comma-separated emails of accounts that are made admins, e.g. the first admin of a new install"},
+	{name: "LOG_FORMAT", def: "json", usage: "log format: json or text"},
+	{name: "LOG_LEVEL", def: "info", usage: "minimum log level, optionally per package, e.g. info,postgres=debug"},
+}
+
+func (s setting) key() string {
+	return strings.ToLower(s.name)
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.key(), "_", "-")
+}
+
+func settingByKey(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key() == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func settingByFlag(name string) (setting, bool) {
+	for _, s := range settings {
+		if s.flagName() == name {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// value is a setting before conversion and where it came from: "default",
+// the configuration file, "environment" or "flag"
+type value struct {
+	raw    string
+	source string
+}
+
+// values are the merged settings by name
+type values map[string]value
+
+func redactAll(string) string {
+	return "[redacted]"
+}
+
+var passwordParam = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// redactConnString hides the password of a URL or key=value connection string
+func redactConnString(conn string) string {
+	if u, err := url.Parse(conn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return passwordParam.ReplaceAllString(conn, "${1}xxxxx")
+}
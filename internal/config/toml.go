@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseFile reads a TOML configuration file. Keys are joined to their table
+// with underscores, so cert_file in a [tls] table is the same as
+// tls_cert_file. Values must be strings, integers, booleans or arrays of
+// strings; anything else, like floats or dates, is rejected.
+func parseFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := map[string]interface{}{}
+	if err := flatten(values, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(values map[string]interface{}, prefix string, table map[string]interface{}) error {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := prefix + key
+		switch v := table[key].(type) {
+		case map[string]interface{}:
+			if err := flatten(values, name+"_", v); err != nil {
+				return err
+			}
+			continue
+		case string, int64, bool:
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("%s: arrays can only hold strings", name)
+				}
+				items = append(items, s)
+			}
+			table[key] = items
+		default:
+			return fmt.Errorf("%s: unsupported value, use a string, integer, boolean or array of strings", name)
+		}
+
+		if _, ok := values[name]; ok {
+			return fmt.Errorf("%s is defined twice", name)
+		}
+		values[name] = table[key]
+	}
+	return nil
+}
+
+// fileValue converts a value read by parseFile to the text of s. Integers
+// of octal settings are written in octal, so that mode = 0o660 is 0660.
+func fileValue(s setting, v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		if s.octal {
+			return "0o" + strconv.FormatInt(v, 8)
+		}
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to a file in a temporary directory
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	path := writeFile(t, `
+# A comment
+store = "postgres"   # trailing comment
+db_conn_string = 'postgres://user@localhost/db?sslmode=disable'
+escaped = "tab\there \"quoted\" \u00e9"
+literal = 'C:\certs\#1'
+port = 3000
+mode = 0o660
+grouped = 1_000
+enabled = true
+listen_addrs = [
+  ":3000",
+  'unix:///run/app.sock', # two
+]
+empty = []
+dotted.key = "x"
+"quoted" = "y"
+inline = { a = "1", b = 2 }
+
+[tls]
+cert_file = "/etc/cert.pem"
+client.ca_file = "/etc/ca.pem"
+
+[log.sub] # nested
+level = "debug"
+`)
+
+	values, err := parseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"store":              "postgres",
+		"db_conn_string":     "postgres://user@localhost/db?sslmode=disable",
+		"escaped":            "tab\there \"quoted\" é",
+		"literal":            `C:\certs\#1`,
+		"port":               int64(3000),
+		"mode":               int64(0o660),
+		"grouped":            int64(1000),
+		"enabled":            true,
+		"listen_addrs":       []string{":3000", "unix:///run/app.sock"},
+		"empty":              []string{},
+		"dotted_key":         "x",
+		"quoted":             "y",
+		"inline_a":           "1",
+		"inline_b":           int64(2),
+		"tls_cert_file":      "/etc/cert.pem",
+		"tls_client_ca_file": "/etc/ca.pem",
+		"log_sub_level":      "debug",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v\nwant %#v", values, want)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing equals", "store", "line 1"},
+		{"bare string", "store = postgres", "line 1"},
+		{"unterminated string", `store = "postgres`, "line 1"},
+		{"leading zero", "port = 03000", "line 1"},
+		{"line number", "# comment\n\nstore = \"a\"\nport =", "line 4"},
+		{"defined twice", "store = \"a\"\nstore = \"b\"", "line 2"},
+		{"float", "ratio = 1.5", "ratio: unsupported value"},
+		{"date", "day = 2024-01-01", "day: unsupported value"},
+		{"array of numbers", "addrs = [1, 2]", "addrs: arrays can only hold strings"},
+		{"array of tables", "[[addrs]]\nhost = \"a\"", "addrs: unsupported value"},
+		{"defined twice through table", "tls_cert_file = \"a\"\n[tls]\ncert_file = \"b\"", "tls_cert_file is defined twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.content)
+			_, err := parseFile(path)
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), path+":") {
+				t.Errorf("error = %q, want it to start with the file name", err)
+			}
+		})
+	}
+}
+
+func TestParseFileMissing(t *testing.T) {
+	if _, err := parseFile(filepath.Join(t.TempDir(), "missing.toml")); !os.IsNotExist(err) {
+		t.Errorf("error = %v, want a not-exist error", err)
+	}
+}
+
+func TestFileValue(t *testing.T) {
+	plain, _ := settingByKey("server_port")
+	octal, _ := settingByKey("socket_mode")
+
+	tests := []struct {
+		s    setting
+		v    interface{}
+		want string
+	}{
+		{plain, "postgres", "postgres"},
+		{plain, int64(3000), "3000"},
+		{plain, true, "true"},
+		{plain, []string{":3000", ":3001"}, ":3000,:3001"},
+		{plain, []string{}, ""},
+		{octal, int64(0o660), "0o660"},
+		{octal, "0660", "0660"},
+	}
+	for _, tt := range tests {
+		if got := fileValue(tt.s, tt.v); got != tt.want {
+			t.Errorf("fileValue(%s, %#v) = %q, want %q", tt.s.name, tt.v, got, tt.want)
+		}
+	}
+}
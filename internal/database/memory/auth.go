@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func (m *MemoryDB) CreateUser(user *types.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Email == user.Email {
+			return apperr.Conflict("email already exists")
+		}
+	}
+
+	user.ID = uuid.NewString()
+	user.Role = types.RoleStudent
+	user.CreatedAt = now()
+	user.UpdatedAt = user.CreatedAt
+
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *MemoryDB) GetUserByEmail(email string) (types.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return types.User{}, apperr.NotFound("user not found")
+}
+
+func (m *MemoryDB) GetUserByID(id string) (types.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return types.User{}, apperr.InvalidInput("invalid user ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return types.User{}, apperr.NotFound("user not found")
+	}
+	return user, nil
+}
+
+func (m *MemoryDB) ListUsers() ([]types.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]types.User, 0, len(m.users))
+	for _, user := range m.users {
+		user.Password = ""
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt < users[j].CreatedAt
+	})
+	return users, nil
+}
+
+func (m *MemoryDB) UpdateUserRole(id string, role string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid user ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return apperr.NotFound("user not found")
+	}
+
+	user.Role = role
+	user.UpdatedAt = now()
+	m.users[id] = user
+	return nil
+}
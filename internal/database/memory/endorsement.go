@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// endorsement is the primary key of skill_endorsements
+type endorsement struct {
+	profileID, skill, userID string
+}
+
+func (m *MemoryDB) EndorseSkill(profileID string, skill string, userID string) (int, error) {
+	if _, err := uuid.Parse(profileID); err != nil {
+		return 0, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[profileID]; !ok {
+		return 0, apperr.NotFound("profile not found")
+	}
+
+	m.endorsements[endorsement{profileID, skill, userID}] = true
+	return m.countEndorsements(profileID, skill), nil
+}
+
+func (m *MemoryDB) RevokeEndorsement(profileID string, skill string, userID string) (int, error) {
+	if _, err := uuid.Parse(profileID); err != nil {
+		return 0, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := endorsement{profileID, skill, userID}
+	if !m.endorsements[key] {
+		return 0, apperr.NotFound("endorsement not found")
+	}
+
+	delete(m.endorsements, key)
+	return m.countEndorsements(profileID, skill), nil
+}
+
+// countEndorsements must be called with the lock held
+func (m *MemoryDB) countEndorsements(profileID string, skill string) int {
+	count := 0
+	for e := range m.endorsements {
+		if e.profileID == profileID && e.skill == skill {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+func (m *MemoryDB) ProfileFacets(filter api.SearchFilters, limit int) (api.Facets, error) {
+	faculty := map[string]int{}
+	fieldOfStudy := map[string]int{}
+	semester := map[string]int{}
+	skills := map[string]int{}
+	focus := map[string]int{}
+	availability := map[string]int{}
+
+	for _, profile := range m.searchProfiles(filter) {
+		faculty[profile.Faculty]++
+		fieldOfStudy[profile.FieldOfStudy]++
+		semester[strconv.Itoa(profile.Semester)]++
+		availability[strconv.FormatBool(profile.IsAvailable)]++
+		for _, skill := range profile.Skills {
+			skills[skill]++
+		}
+		for _, f := range profile.Focus {
+			focus[f]++
+		}
+	}
+
+	facets := api.Facets{
+		Faculty:      facetCounts(faculty, 0),
+		FieldOfStudy: facetCounts(fieldOfStudy, 0),
+		Semester:     facetCounts(semester, 0),
+		Skills:       facetCounts(skills, limit),
+		Focus:        facetCounts(focus, limit),
+		Availability: facetCounts(availability, 0),
+	}
+	// Semesters are listed in order like the postgres query does
+	slices.SortFunc(facets.Semester, func(a, b api.FacetCount) int {
+		x, _ := strconv.Atoi(a.Value)
+		y, _ := strconv.Atoi(b.Value)
+		return cmp.Compare(x, y)
+	})
+
+	return facets, nil
+}
+
+// facetCounts sorts counts by count and value and keeps the first limit,
+// or all of them for a limit of 0
+func facetCounts(counts map[string]int, limit int) []api.FacetCount {
+	result := make([]api.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, api.FacetCount{Value: value, Count: count})
+	}
+	slices.SortFunc(result, func(a, b api.FacetCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,488 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// timestampFormat has a fixed width so that timestamps sort as strings
+const timestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// MemoryDB is a thread-safe, non-persistent implementation of api.Database
+// with the same semantics and errors as postgres.PostgresDB. It is meant for
+// tests and local development.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	profiles map[string]api.StudentProfile
+	users    map[string]types.User
+	tokens   map[string]types.RefreshToken
+	teams    map[string]api.Team
+	members  map[string][]api.TeamMember
+	requests map[string]teamRequest
+	terms    map[string]api.Term
+
+	endorsements map[endorsement]bool
+}
+
+var _ api.Database = (*MemoryDB)(nil)
+
+// teamRequest keeps the expiry as a time so that it can be compared
+type teamRequest struct {
+	api.TeamRequest
+	expiresAt time.Time
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		profiles: make(map[string]api.StudentProfile),
+		users:    make(map[string]types.User),
+		tokens:   make(map[string]types.RefreshToken),
+		teams:    make(map[string]api.Team),
+		members:  make(map[string][]api.TeamMember),
+		requests: make(map[string]teamRequest),
+		terms:    make(map[string]api.Term),
+
+		endorsements: make(map[endorsement]bool),
+	}
+}
+
+// Close does nothing, the data is simply dropped with the MemoryDB
+func (m *MemoryDB) Close() error {
+	return nil
+}
+
+func now() string {
+	return time.Now().UTC().Format(timestampFormat)
+}
+
+func copyStrings(s []string) []string {
+	return append(make([]string, 0, len(s)), s...)
+}
+
+func copyProfile(profile api.StudentProfile) api.StudentProfile {
+	profile.Skills = copyStrings(profile.Skills)
+	profile.Focus = copyStrings(profile.Focus)
+	profile.SkillLevels = append(make([]api.SkillLevel, 0, len(profile.SkillLevels)), profile.SkillLevels...)
+	profile.Endorsements = nil
+	profile.Match = nil
+	return profile
+}
+
+// profile returns a copy of a stored profile with its endorsements counted
+// like the postgres query does, only for skills the profile lists. Callers
+// must hold the lock.
+func (m *MemoryDB) profile(profile api.StudentProfile) api.StudentProfile {
+	profile = copyProfile(profile)
+	profile.Endorsements = map[string]int{}
+	for e := range m.endorsements {
+		if e.profileID == profile.ID && slices.Contains(profile.Skills, e.skill) {
+			profile.Endorsements[e.skill]++
+		}
+	}
+	return profile
+}
+
+func (m *MemoryDB) CreateProfile(profile *api.StudentProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.profiles {
+		if profile.UserID != "" && existing.UserID == profile.UserID {
+			return apperr.Conflict("profile already exists")
+		}
+		if existing.Email == profile.Email {
+			return apperr.Conflict("email already exists")
+		}
+	}
+
+	profile.ID = uuid.NewString()
+	profile.CreatedAt = now()
+	profile.UpdatedAt = profile.CreatedAt
+	profile.Version = 1
+	profile.Skills = copyStrings(profile.Skills)
+	profile.Focus = copyStrings(profile.Focus)
+
+	m.profiles[profile.ID] = copyProfile(*profile)
+	profile.Endorsements = map[string]int{}
+	return nil
+}
+
+func (m *MemoryDB) GetProfile(id string) (api.StudentProfile, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return api.StudentProfile{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	profile, ok := m.profiles[id]
+	if !ok {
+		return api.StudentProfile{}, apperr.NotFound("profile not found")
+	}
+	return m.profile(profile), nil
+}
+
+func (m *MemoryDB) GetProfileByUserID(userID string) (api.StudentProfile, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return api.StudentProfile{}, apperr.InvalidInput("invalid user ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, profile := range m.profiles {
+		if profile.UserID == userID {
+			return m.profile(profile), nil
+		}
+	}
+	return api.StudentProfile{}, apperr.NotFound("profile not found")
+}
+
+// UpdateProfile overwrites the editable fields; like the postgres
+// implementation it leaves the owner and creation time alone and checks the
+// version unless it is 0
+func (m *MemoryDB) UpdateProfile(id string, profile *api.StudentProfile, version int) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.profiles[id]
+	if !ok {
+		return apperr.NotFound("profile not found")
+	}
+	if version != 0 && existing.Version != version {
+		return apperr.PreconditionFailed("profile was modified since version %d", version)
+	}
+
+	for otherID, other := range m.profiles {
+		if otherID != id && other.Email == profile.Email {
+			return apperr.Conflict("email already exists")
+		}
+	}
+
+	existing.Name = profile.Name
+	existing.Email = profile.Email
+	existing.Faculty = profile.Faculty
+	existing.FieldOfStudy = profile.FieldOfStudy
+	existing.Semester = profile.Semester
+	existing.Skills = copyStrings(profile.Skills)
+	existing.Focus = copyStrings(profile.Focus)
+	existing.Bio = profile.Bio
+	existing.SkillLevels = append([]api.SkillLevel(nil), profile.SkillLevels...)
+	existing.IsAvailable = profile.IsAvailable
+	existing.UpdatedAt = now()
+	existing.Version++
+
+	m.profiles[id] = existing
+	*profile = m.profile(existing)
+	return nil
+}
+
+func (m *MemoryDB) DeleteProfile(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[id]; !ok {
+		return apperr.NotFound("profile not found")
+	}
+
+	delete(m.profiles, id)
+
+	// Same as ON DELETE CASCADE on team_members, team_requests and
+	// skill_endorsements
+	for teamID, members := range m.members {
+		kept := members[:0]
+		for _, member := range members {
+			if member.ProfileID != id {
+				kept = append(kept, member)
+			}
+		}
+		m.members[teamID] = kept
+		m.refreshTeamStatus(teamID)
+	}
+	for requestID, req := range m.requests {
+		if req.ProfileID == id {
+			delete(m.requests, requestID)
+		}
+	}
+	for e := range m.endorsements {
+		if e.profileID == id {
+			delete(m.endorsements, e)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryDB) GetAllProfiles(opts api.ListOptions) (api.ProfilePage, error) {
+	return m.SearchProfiles(api.SearchFilters{}, opts)
+}
+
+func (m *MemoryDB) SearchProfiles(filter api.SearchFilters, opts api.ListOptions) (api.ProfilePage, error) {
+	matched := m.searchProfiles(filter)
+
+	items, err := paginate(matched, opts, func(p api.StudentProfile) sortKey {
+		return profileSortKey(p, opts.Sort)
+	})
+	if err != nil {
+		return api.ProfilePage{}, err
+	}
+
+	return api.NewProfilePage(opts, items, len(matched)), nil
+}
+
+// searchProfiles returns copies of every profile matching filter, with
+// their match when filter has a q
+func (m *MemoryDB) searchProfiles(filter api.SearchFilters) []api.StudentProfile {
+	words := api.QueryWords(filter.Query)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []api.StudentProfile
+	for _, profile := range m.profiles {
+		if !matchesFilters(profile, filter) {
+			continue
+		}
+		profile = m.profile(profile)
+		if len(words) > 0 {
+			var ok bool
+			if profile.Match, ok = matchText(profile, words); !ok {
+				continue
+			}
+		}
+		matched = append(matched, profile)
+	}
+	return matched
+}
+
+func (m *MemoryDB) FindMatchCandidates(q api.MatchQuery, limit int) ([]api.StudentProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var candidates []api.StudentProfile
+	score := map[string]float64{}
+	for _, profile := range m.profiles {
+		if q.OnlyAvailable && !profile.IsAvailable {
+			continue
+		}
+		if q.ExcludeUserID != "" && profile.UserID == q.ExcludeUserID {
+			continue
+		}
+		if overlaps(profile.Skills, q.Skills) || overlaps(profile.Focus, q.Focus) {
+			candidates = append(candidates, m.profile(profile))
+			score[profile.ID] = q.Score(profile)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if score[a.ID] != score[b.ID] {
+			return score[a.ID] > score[b.ID]
+		}
+		if a.UpdatedAt != b.UpdatedAt {
+			return a.UpdatedAt > b.UpdatedAt
+		}
+		return a.ID < b.ID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+func matchesFilters(profile api.StudentProfile, filter api.SearchFilters) bool {
+	if filter.Faculty != "" && profile.Faculty != filter.Faculty {
+		return false
+	}
+	if filter.FieldOfStudy != "" && profile.FieldOfStudy != filter.FieldOfStudy {
+		return false
+	}
+	if len(filter.Skills) > 0 && !matchesArray(profile.Skills, filter.Skills, filter.SkillsMatch) {
+		return false
+	}
+	if filter.MinProficiency != "" && len(filter.Skills) > 0 && !matchesProficiency(profile.SkillLevels, filter) {
+		return false
+	}
+	if len(filter.ExcludeSkills) > 0 && overlaps(profile.Skills, filter.ExcludeSkills) {
+		return false
+	}
+	if len(filter.Focus) > 0 && !matchesArray(profile.Focus, filter.Focus, filter.FocusMatch) {
+		return false
+	}
+	if len(filter.ExcludeFocus) > 0 && overlaps(profile.Focus, filter.ExcludeFocus) {
+		return false
+	}
+	if filter.SemesterMin > 0 && profile.Semester < filter.SemesterMin {
+		return false
+	}
+	if filter.SemesterMax > 0 && profile.Semester > filter.SemesterMax {
+		return false
+	}
+	if filter.Availability != nil && profile.IsAvailable != *filter.Availability {
+		return false
+	}
+	return true
+}
+
+// matchesArray mirrors the && (any) and @> (all) array operators
+func matchesArray(have, want []string, match string) bool {
+	if match == api.MatchAll {
+		return contains(have, want)
+	}
+	return overlaps(have, want)
+}
+
+// matchesProficiency checks the levels of the wanted skills against the
+// minimum, for any or all of them
+func matchesProficiency(levels []api.SkillLevel, filter api.SearchFilters) bool {
+	var proficient []string
+	for _, level := range levels {
+		if api.ProficiencyRank(level.Level) >= api.ProficiencyRank(filter.MinProficiency) {
+			proficient = append(proficient, level.Skill)
+		}
+	}
+	return matchesArray(proficient, filter.Skills, filter.SkillsMatch)
+}
+
+func overlaps(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(have, want []string) bool {
+	for _, w := range want {
+		if !overlaps(have, []string{w}) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKey is the value an item is ordered by, with its ID as tie-breaker
+type sortKey struct {
+	str  string
+	num  int
+	rank float64
+	id   string
+}
+
+func compareKeys(a, b sortKey) int {
+	if a.rank != b.rank {
+		if a.rank < b.rank {
+			return -1
+		}
+		return 1
+	}
+	if a.num != b.num {
+		if a.num < b.num {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(a.str, b.str); c != 0 {
+		return c
+	}
+	return strings.Compare(a.id, b.id)
+}
+
+func profileSortKey(profile api.StudentProfile, sort string) sortKey {
+	switch sort {
+	case api.SortName:
+		return sortKey{str: profile.Name, id: profile.ID}
+	case api.SortSemester:
+		return sortKey{num: profile.Semester, id: profile.ID}
+	case api.SortRelevance:
+		if profile.Match == nil {
+			return sortKey{id: profile.ID}
+		}
+		return sortKey{rank: profile.Match.Rank, id: profile.ID}
+	default:
+		return sortKey{str: profile.CreatedAt, id: profile.ID}
+	}
+}
+
+func cursorKey(cursor api.Cursor) (sortKey, error) {
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return sortKey{}, apperr.InvalidInput("invalid cursor")
+	}
+
+	if cursor.Sort == api.SortSemester {
+		num, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return sortKey{}, apperr.InvalidInput("invalid cursor")
+		}
+		return sortKey{num: num, id: cursor.ID}, nil
+	}
+	if cursor.Sort == api.SortRelevance {
+		rank, err := strconv.ParseFloat(cursor.Value, 32)
+		if err != nil {
+			return sortKey{}, apperr.InvalidInput("invalid cursor")
+		}
+		return sortKey{rank: rank, id: cursor.ID}, nil
+	}
+
+	return sortKey{str: cursor.Value, id: cursor.ID}, nil
+}
+
+// paginate sorts items and returns up to opts.Limit+1 of them after the
+// cursor or offset, which is what api.NewProfilePage and api.NewTeamPage expect
+func paginate[T any](items []T, opts api.ListOptions, key func(T) sortKey) ([]T, error) {
+	sort.Slice(items, func(i, j int) bool {
+		c := compareKeys(key(items[i]), key(items[j]))
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if opts.After != nil {
+		after, err := cursorKey(*opts.After)
+		if err != nil {
+			return nil, err
+		}
+
+		start := len(items)
+		for i, item := range items {
+			c := compareKeys(key(item), after)
+			if (!opts.Desc && c > 0) || (opts.Desc && c < 0) {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	if opts.Offset >= len(items) {
+		return nil, nil
+	}
+	items = items[opts.Offset:]
+
+	if len(items) > opts.Limit+1 {
+		items = items[:opts.Limit+1]
+	}
+	return items, nil
+}
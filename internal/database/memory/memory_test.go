@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+func TestFindMatchCandidatesRanksByScore(t *testing.T) {
+	db := NewMemoryDB()
+
+	profiles := []struct {
+		userID    string
+		skills    []string
+		focus     []string
+		available bool
+	}{
+		{"", []string{"Go"}, nil, true},
+		{"", []string{"Go", "SQL"}, []string{"Backend"}, true},
+		{"", []string{"SQL"}, nil, false},
+		{"", []string{"React"}, []string{"Frontend"}, true},
+		{"requester", []string{"Go", "SQL"}, []string{"Backend"}, true},
+	}
+	ids := make([]string, len(profiles))
+	for i, p := range profiles {
+		profile := &api.StudentProfile{
+			UserID:      p.userID,
+			Email:       fmt.Sprintf("student%d@example.com", i),
+			Skills:      p.skills,
+			Focus:       p.focus,
+			IsAvailable: p.available,
+		}
+		if err := db.CreateProfile(profile); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = profile.ID
+	}
+
+	query := api.MatchQuery{
+		Skills:         []string{"Go", "SQL"},
+		SkillScores:    []float64{0.6, 0.1},
+		Focus:          []string{"Backend"},
+		FocusScores:    []float64{0.2},
+		AvailableScore: 0.1,
+		ExcludeUserID:  "requester",
+	}
+
+	tests := []struct {
+		name          string
+		onlyAvailable bool
+		limit         int
+		want          []string
+	}{
+		{"highest score first", false, 10, []string{ids[1], ids[0], ids[2]}},
+		{"limited", false, 1, []string{ids[1]}},
+		{"only available", true, 10, []string{ids[1], ids[0]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := query
+			q.OnlyAvailable = tt.onlyAvailable
+			candidates, err := db.FindMatchCandidates(q, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range candidates {
+				got = append(got, c.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("candidates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
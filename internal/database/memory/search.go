@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+// searchField is a field of a profile that q is matched against, weighted
+// like the postgres search_vector
+type searchField struct {
+	name   string
+	text   string
+	weight float64
+}
+
+// matchText approximates the postgres text search: every word of the query
+// has to be a prefix of a word of the profile, or be at most one or two
+// edits away from one. Only prefix matches are highlighted.
+func matchText(profile api.StudentProfile, words []string) (*api.SearchMatch, bool) {
+	fields := []searchField{
+		{"name", profile.Name, 1},
+		{"field_of_study", profile.FieldOfStudy, 0.4},
+		{"skills", strings.Join(profile.Skills, ", "), 1},
+		{"focus", strings.Join(profile.Focus, ", "), 0.4},
+		{"bio", profile.Bio, 0.2},
+	}
+
+	best := make([]float64, len(words))
+	match := &api.SearchMatch{Highlights: map[string]string{}}
+
+	for _, field := range fields {
+		var highlighted strings.Builder
+		marked := false
+		last := 0
+
+		for _, span := range wordSpans(field.text) {
+			word := strings.ToLower(field.text[span[0]:span[1]])
+			prefix := false
+			for i, q := range words {
+				switch {
+				case strings.HasPrefix(word, q):
+					prefix = true
+					best[i] = max(best[i], field.weight)
+				case isTypo(word, q):
+					best[i] = max(best[i], field.weight/2)
+				}
+			}
+			if prefix {
+				highlighted.WriteString(field.text[last:span[0]])
+				highlighted.WriteString(api.HighlightStart + field.text[span[0]:span[1]] + api.HighlightStop)
+				last = span[1]
+				marked = true
+			}
+		}
+
+		if marked {
+			highlighted.WriteString(field.text[last:])
+			match.Highlights[field.name] = api.Highlight(highlighted.String())
+		}
+	}
+
+	var rank float64
+	for _, score := range best {
+		if score == 0 {
+			return nil, false
+		}
+		rank += score
+	}
+	// Ranks are single precision like in postgres, so that they survive a
+	// round trip through a cursor
+	match.Rank = float64(float32(rank / float64(len(words))))
+
+	return match, true
+}
+
+// wordSpans returns the start and end of every word of text
+func wordSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}
+
+// isTypo allows one edit for words of four or more letters and two for
+// words of eight or more
+func isTypo(word, q string) bool {
+	n := len([]rune(q))
+	switch {
+	case n >= 8:
+		return editDistance(word, q) <= 2
+	case n >= 4:
+		return editDistance(word, q) <= 1
+	default:
+		return false
+	}
+}
+
+// editDistance is the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
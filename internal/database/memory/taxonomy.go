@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+func copyTerm(term api.Term) api.Term {
+	term.Aliases = copyStrings(term.Aliases)
+	term.Synonyms = copyStrings(term.Synonyms)
+	return term
+}
+
+// termNames returns the lower-cased name, aliases and synonyms of a term
+func termNames(term api.Term) []string {
+	names := []string{strings.ToLower(term.Name)}
+	for _, alias := range append(append([]string{}, term.Aliases...), term.Synonyms...) {
+		names = append(names, strings.ToLower(alias))
+	}
+	return names
+}
+
+// checkTermNames must be called with the lock held
+func (m *MemoryDB) checkTermNames(term *api.Term, id string) error {
+	for _, other := range m.terms {
+		if other.ID == id || other.Kind != term.Kind {
+			continue
+		}
+		for _, name := range termNames(*term) {
+			if slices.Contains(termNames(other), name) {
+				return apperr.Conflict("%q is already used by another %s", name, term.Kind)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryDB) CreateTerm(term *api.Term) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkTermNames(term, ""); err != nil {
+		return err
+	}
+
+	term.ID = uuid.NewString()
+	term.CreatedAt = now()
+	term.UpdatedAt = term.CreatedAt
+	*term = copyTerm(*term)
+
+	m.terms[term.ID] = copyTerm(*term)
+	return nil
+}
+
+func (m *MemoryDB) GetTerm(id string) (api.Term, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return api.Term{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	term, ok := m.terms[id]
+	if !ok {
+		return api.Term{}, apperr.NotFound("term not found")
+	}
+	return copyTerm(term), nil
+}
+
+func (m *MemoryDB) UpdateTerm(id string, term *api.Term) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.terms[id]
+	if !ok {
+		return apperr.NotFound("term not found")
+	}
+	if err := m.checkTermNames(term, id); err != nil {
+		return err
+	}
+
+	existing.Name = term.Name
+	existing.Category = term.Category
+	existing.Aliases = copyStrings(term.Aliases)
+	existing.Synonyms = copyStrings(term.Synonyms)
+	existing.UpdatedAt = now()
+
+	m.terms[id] = existing
+	*term = copyTerm(existing)
+	return nil
+}
+
+func (m *MemoryDB) DeleteTerm(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.terms[id]; !ok {
+		return apperr.NotFound("term not found")
+	}
+	delete(m.terms, id)
+	return nil
+}
+
+// SearchTerms ranks like the postgres implementation: exact name matches,
+// then name prefix matches, then alias matches, each by name
+func (m *MemoryDB) SearchTerms(kind string, prefix string, limit int) ([]api.Term, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix = strings.ToLower(prefix)
+	rank := func(term api.Term) int {
+		name := strings.ToLower(term.Name)
+		switch {
+		case name == prefix:
+			return 0
+		case strings.HasPrefix(name, prefix):
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	terms := []api.Term{}
+	for _, term := range m.terms {
+		if term.Kind != kind {
+			continue
+		}
+		for _, name := range termNames(term) {
+			if strings.HasPrefix(name, prefix) {
+				terms = append(terms, copyTerm(term))
+				break
+			}
+		}
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		if ri, rj := rank(terms[i]), rank(terms[j]); ri != rj {
+			return ri < rj
+		}
+		return strings.ToLower(terms[i].Name) < strings.ToLower(terms[j].Name)
+	})
+
+	if limit > 0 && len(terms) > limit {
+		terms = terms[:limit]
+	}
+	return terms, nil
+}
+
+func (m *MemoryDB) TermLookup(kind string) (api.TermLookup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.termLookup(kind), nil
+}
+
+// termLookup must be called with the lock held
+func (m *MemoryDB) termLookup(kind string) api.TermLookup {
+	lookup := api.TermLookup{}
+	for _, term := range m.terms {
+		if term.Kind != kind {
+			continue
+		}
+		for _, name := range termNames(term) {
+			lookup[name] = term.Name
+		}
+	}
+	return lookup
+}
+
+func (m *MemoryDB) MergeTerms(sourceID string, targetID string) (api.MergeResult, error) {
+	if _, err := uuid.Parse(sourceID); err != nil {
+		return api.MergeResult{}, apperr.InvalidInput("invalid ID format")
+	}
+	if _, err := uuid.Parse(targetID); err != nil {
+		return api.MergeResult{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.terms[sourceID]
+	if !ok {
+		return api.MergeResult{}, apperr.NotFound("term not found")
+	}
+	target, ok := m.terms[targetID]
+	if !ok {
+		return api.MergeResult{}, apperr.NotFound("term not found")
+	}
+	if source.Kind != target.Kind {
+		return api.MergeResult{}, apperr.InvalidInput("can't merge a %s into a %s", source.Kind, target.Kind)
+	}
+
+	target.Aliases = append(append(copyStrings(target.Aliases), source.Name), source.Aliases...)
+	target.Synonyms = append(copyStrings(target.Synonyms), source.Synonyms...)
+	sort.Slice(target.Aliases, func(i, j int) bool {
+		return strings.ToLower(target.Aliases[i]) < strings.ToLower(target.Aliases[j])
+	})
+	sort.Slice(target.Synonyms, func(i, j int) bool {
+		return strings.ToLower(target.Synonyms[i]) < strings.ToLower(target.Synonyms[j])
+	})
+	target.UpdatedAt = now()
+
+	delete(m.terms, sourceID)
+	m.terms[targetID] = target
+
+	return api.MergeResult{Term: copyTerm(target), RetagResult: m.retag()}, nil
+}
+
+func (m *MemoryDB) Retag() (api.RetagResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.retag(), nil
+}
+
+// retag must be called with the lock held
+func (m *MemoryDB) retag() api.RetagResult {
+	skills := m.termLookup(api.TermSkill)
+	focus := m.termLookup(api.TermFocus)
+
+	return api.RetagResult{
+		Retagged:      m.retagProfiles(skills, focus),
+		RetaggedTeams: m.retagTeams(skills, focus),
+	}
+}
+
+func (m *MemoryDB) retagProfiles(skills, focus api.TermLookup) int {
+	retagged := 0
+	for id, profile := range m.profiles {
+		nextSkills := skills.Canonical(profile.Skills)
+		nextFocus := focus.Canonical(profile.Focus)
+		nextLevels := skills.CanonicalLevels(profile.SkillLevels)
+		if slices.Equal(nextSkills, profile.Skills) && slices.Equal(nextFocus, profile.Focus) && slices.Equal(nextLevels, profile.SkillLevels) {
+			continue
+		}
+
+		profile.Skills = nextSkills
+		profile.Focus = nextFocus
+		profile.SkillLevels = nextLevels
+		profile.Version++
+		profile.UpdatedAt = now()
+		m.profiles[id] = profile
+		retagged++
+	}
+
+	// Move endorsements to the canonical name, dropping duplicates
+	for e := range m.endorsements {
+		if name := skills.Name(e.skill); name != e.skill {
+			delete(m.endorsements, e)
+			e.skill = name
+			m.endorsements[e] = true
+		}
+	}
+
+	return retagged
+}
+
+func (m *MemoryDB) retagTeams(skills, focus api.TermLookup) int {
+	retagged := 0
+	for id, team := range m.teams {
+		nextSkills := skills.Canonical(team.RequiredSkills)
+		nextFocus := focus.Canonical(team.Focus)
+		if slices.Equal(nextSkills, team.RequiredSkills) && slices.Equal(nextFocus, team.Focus) {
+			continue
+		}
+
+		team.RequiredSkills = nextSkills
+		team.Focus = nextFocus
+		team.UpdatedAt = now()
+		m.teams[id] = team
+		retagged++
+	}
+	return retagged
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// team returns a copy of a stored team with its member count filled in.
+// Callers must hold the lock.
+func (m *MemoryDB) team(id string) (api.Team, bool) {
+	team, ok := m.teams[id]
+	if !ok {
+		return api.Team{}, false
+	}
+	team.RequiredSkills = copyStrings(team.RequiredSkills)
+	team.Focus = copyStrings(team.Focus)
+	team.MemberCount = len(m.members[id])
+	return team, true
+}
+
+// refreshTeamStatus is the counterpart of the postgres helper. Callers must
+// hold the write lock.
+func (m *MemoryDB) refreshTeamStatus(id string) {
+	team, ok := m.teams[id]
+	if !ok || team.Status == api.TeamStatusClosed {
+		return
+	}
+
+	if len(m.members[id]) >= team.MaxSize {
+		team.Status = api.TeamStatusFull
+	} else {
+		team.Status = api.TeamStatusOpen
+	}
+	m.teams[id] = team
+}
+
+func (m *MemoryDB) CreateTeam(team *api.Team, ownerProfileID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[ownerProfileID]; !ok {
+		return apperr.NotFound("profile not found")
+	}
+
+	stored := *team
+	stored.ID = uuid.NewString()
+	stored.RequiredSkills = copyStrings(team.RequiredSkills)
+	stored.Focus = copyStrings(team.Focus)
+	stored.CreatedAt = now()
+	stored.UpdatedAt = stored.CreatedAt
+	m.teams[stored.ID] = stored
+
+	m.members[stored.ID] = []api.TeamMember{{
+		TeamID:    stored.ID,
+		ProfileID: ownerProfileID,
+		Role:      api.TeamRoleOwner,
+		JoinedAt:  stored.CreatedAt,
+	}}
+	m.refreshTeamStatus(stored.ID)
+
+	*team, _ = m.team(stored.ID)
+	return nil
+}
+
+func (m *MemoryDB) GetTeam(id string) (api.Team, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return api.Team{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	team, ok := m.team(id)
+	if !ok {
+		return api.Team{}, apperr.NotFound("team not found")
+	}
+	return team, nil
+}
+
+func (m *MemoryDB) UpdateTeam(id string, team *api.Team) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.teams[id]
+	if !ok {
+		return apperr.NotFound("team not found")
+	}
+	if members := len(m.members[id]); team.MaxSize < members {
+		return apperr.Conflict("the team already has %d members, more than max_size", members)
+	}
+
+	existing.Name = team.Name
+	existing.Description = team.Description
+	existing.RequiredSkills = copyStrings(team.RequiredSkills)
+	existing.Focus = copyStrings(team.Focus)
+	existing.MaxSize = team.MaxSize
+	existing.Status = team.Status
+	existing.UpdatedAt = now()
+	m.teams[id] = existing
+	m.refreshTeamStatus(id)
+
+	*team, _ = m.team(id)
+	return nil
+}
+
+func (m *MemoryDB) DeleteTeam(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.teams[id]; !ok {
+		return apperr.NotFound("team not found")
+	}
+
+	delete(m.teams, id)
+	delete(m.members, id)
+	for requestID, req := range m.requests {
+		if req.TeamID == id {
+			delete(m.requests, requestID)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryDB) SearchTeams(filter api.TeamSearchFilters, opts api.ListOptions) (api.TeamPage, error) {
+	m.mu.RLock()
+	var matched []api.Team
+	for id := range m.teams {
+		team, _ := m.team(id)
+		if len(filter.Skills) > 0 && !matchesArray(team.RequiredSkills, filter.Skills, filter.SkillsMatch) {
+			continue
+		}
+		if len(filter.Focus) > 0 && !matchesArray(team.Focus, filter.Focus, filter.FocusMatch) {
+			continue
+		}
+		if filter.Status != "" && team.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, team)
+	}
+	m.mu.RUnlock()
+
+	items, err := paginate(matched, opts, func(t api.Team) sortKey {
+		if opts.Sort == api.SortName {
+			return sortKey{str: t.Name, id: t.ID}
+		}
+		return sortKey{str: t.CreatedAt, id: t.ID}
+	})
+	if err != nil {
+		return api.TeamPage{}, err
+	}
+
+	return api.NewTeamPage(opts, items, len(matched)), nil
+}
+
+func (m *MemoryDB) GetTeamMembers(teamID string) ([]api.TeamMember, error) {
+	if _, err := uuid.Parse(teamID); err != nil {
+		return nil, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	members := []api.TeamMember{}
+	for _, member := range m.members[teamID] {
+		member.Name = m.profiles[member.ProfileID].Name
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (m *MemoryDB) RemoveTeamMember(teamID string, profileID string) error {
+	if _, err := uuid.Parse(teamID); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+	if _, err := uuid.Parse(profileID); err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := m.members[teamID]
+	for i, member := range members {
+		if member.ProfileID != profileID {
+			continue
+		}
+		if member.Role == api.TeamRoleOwner {
+			return apperr.Conflict("team owner can't leave the team")
+		}
+
+		m.members[teamID] = append(members[:i:i], members[i+1:]...)
+		m.refreshTeamStatus(teamID)
+		return nil
+	}
+
+	return apperr.NotFound("team member not found")
+}
+
+// addTeamMember is the counterpart of the postgres helper. Callers must hold
+// the write lock.
+func (m *MemoryDB) addTeamMember(teamID, profileID string) error {
+	team, ok := m.teams[teamID]
+	if !ok {
+		return apperr.NotFound("team not found")
+	}
+
+	if team.Status == api.TeamStatusClosed {
+		return apperr.Conflict("team is closed")
+	}
+
+	members := m.members[teamID]
+	for _, member := range members {
+		if member.ProfileID == profileID {
+			return nil
+		}
+	}
+
+	if len(members) >= team.MaxSize {
+		return apperr.Conflict("team is full")
+	}
+
+	m.members[teamID] = append(members, api.TeamMember{
+		TeamID:    teamID,
+		ProfileID: profileID,
+		Role:      api.TeamRoleMember,
+		JoinedAt:  now(),
+	})
+	m.refreshTeamStatus(teamID)
+	return nil
+}
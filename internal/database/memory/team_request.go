@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// view returns the request as the API sees it: pending requests past their
+// expiry are reported as expired
+func (req teamRequest) view(teamName string) api.TeamRequest {
+	out := req.TeamRequest
+	out.TeamName = teamName
+	if out.Status == api.RequestPending && time.Now().After(req.expiresAt) {
+		out.Status = api.RequestExpired
+	}
+	return out
+}
+
+func (m *MemoryDB) CreateTeamRequest(req *api.TeamRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, ok := m.teams[req.TeamID]
+	if !ok {
+		return apperr.NotFound("team not found")
+	}
+
+	for _, member := range m.members[req.TeamID] {
+		if member.ProfileID == req.ProfileID {
+			return apperr.Conflict("already a team member")
+		}
+	}
+
+	for id, existing := range m.requests {
+		if existing.TeamID != req.TeamID || existing.ProfileID != req.ProfileID || existing.Status != api.RequestPending {
+			continue
+		}
+		if !time.Now().After(existing.expiresAt) {
+			return apperr.Conflict("team request already pending")
+		}
+		existing.Status = api.RequestExpired
+		existing.UpdatedAt = now()
+		m.requests[id] = existing
+	}
+
+	created := teamRequest{
+		TeamRequest: *req,
+		expiresAt:   time.Now().Add(api.TeamRequestTTL),
+	}
+	created.ID = uuid.NewString()
+	created.Status = api.RequestPending
+	created.ExpiresAt = created.expiresAt.UTC().Format(timestampFormat)
+	created.CreatedAt = now()
+	created.UpdatedAt = created.CreatedAt
+
+	m.requests[created.ID] = created
+
+	*req = created.view(team.Name)
+	return nil
+}
+
+func (m *MemoryDB) GetTeamRequest(id string) (api.TeamRequest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return api.TeamRequest{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return api.TeamRequest{}, apperr.NotFound("team request not found")
+	}
+	return req.view(m.teams[req.TeamID].Name), nil
+}
+
+func (m *MemoryDB) TransitionTeamRequest(id string, status string) (api.TeamRequest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return api.TeamRequest{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return api.TeamRequest{}, apperr.NotFound("team request not found")
+	}
+	teamName := m.teams[req.TeamID].Name
+
+	if req.Status == status {
+		return req.view(teamName), nil
+	}
+
+	if req.Status == api.RequestPending && time.Now().After(req.expiresAt) {
+		req.Status = api.RequestExpired
+		req.UpdatedAt = now()
+		m.requests[id] = req
+		return api.TeamRequest{}, apperr.Conflict("team request expired")
+	}
+
+	if req.Status != api.RequestPending {
+		return api.TeamRequest{}, apperr.Conflict("team request already %s", req.Status)
+	}
+
+	if status == api.RequestAccepted {
+		if err := m.addTeamMember(req.TeamID, req.ProfileID); err != nil {
+			return api.TeamRequest{}, err
+		}
+	}
+
+	req.Status = status
+	req.UpdatedAt = now()
+	m.requests[id] = req
+
+	return req.view(teamName), nil
+}
+
+func (m *MemoryDB) ListTeamRequests(filter api.TeamRequestFilter) ([]api.TeamRequest, error) {
+	profileKind, ownerKind := api.TeamRequestInvitation, api.TeamRequestJoin
+	if filter.Direction == api.DirectionOutgoing {
+		profileKind, ownerKind = api.TeamRequestJoin, api.TeamRequestInvitation
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	requests := []api.TeamRequest{}
+	for _, req := range m.requests {
+		team := m.teams[req.TeamID]
+
+		mine := (req.Kind == profileKind && filter.ProfileID != "" && req.ProfileID == filter.ProfileID) ||
+			(req.Kind == ownerKind && filter.OwnerID != "" && team.OwnerID == filter.OwnerID)
+		if !mine {
+			continue
+		}
+
+		view := req.view(team.Name)
+		if filter.Status != "" && view.Status != filter.Status {
+			continue
+		}
+		requests = append(requests, view)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt > requests[j].CreatedAt
+	})
+	return requests, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func (m *MemoryDB) CreateRefreshToken(token *types.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.storeRefreshToken(token)
+	return nil
+}
+
+func (m *MemoryDB) storeRefreshToken(token *types.RefreshToken) {
+	if token.ID == "" {
+		token.ID = uuid.NewString()
+	}
+	token.CreatedAt = time.Now()
+	m.tokens[token.ID] = *token
+}
+
+func (m *MemoryDB) GetRefreshTokenByHash(hash string) (types.RefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, token := range m.tokens {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+	return types.RefreshToken{}, apperr.NotFound("refresh token not found")
+}
+
+func (m *MemoryDB) RotateRefreshToken(oldID string, next *types.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return apperr.Conflict("refresh token already used")
+	}
+
+	if next.ID == "" {
+		next.ID = uuid.NewString()
+	}
+
+	revokedAt := time.Now()
+	old.RevokedAt = &revokedAt
+	old.ReplacedBy = next.ID
+	m.tokens[oldID] = old
+
+	m.storeRefreshToken(next)
+	return nil
+}
+
+func (m *MemoryDB) RevokeRefreshTokenFamily(familyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revokeTokens(func(token types.RefreshToken) bool {
+		return token.FamilyID == familyID
+	})
+	return nil
+}
+
+func (m *MemoryDB) RevokeUserRefreshTokens(userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revokeTokens(func(token types.RefreshToken) bool {
+		return token.UserID == userID
+	})
+	return nil
+}
+
+func (m *MemoryDB) revokeTokens(match func(types.RefreshToken) bool) {
+	revokedAt := time.Now()
+	for id, token := range m.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &revokedAt
+			m.tokens[id] = token
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/cmd/migrate/migrations"
+)
+
+func testFS() fstest.MapFS {
+	fsys := fstest.MapFS{
+		"embed.go":  {Data: []byte("package migrations")},
+		"README.md": {Data: []byte("not a migration")},
+	}
+	for _, name := range []string{"000001_users", "000002_profiles", "000010_teams"} {
+		fsys[name+".up.sql"] = &fstest.MapFile{Data: []byte("up " + name)}
+		fsys[name+".down.sql"] = &fstest.MapFile{Data: []byte("down " + name)}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	loaded, err := Load(testFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "users", Up: "up 000001_users", Down: "down 000001_users"},
+		{Version: 2, Name: "profiles", Up: "up 000002_profiles", Down: "down 000002_profiles"},
+		{Version: 10, Name: "teams", Up: "up 000010_teams", Down: "down 000010_teams"},
+	}
+	if len(loaded) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d", len(loaded), len(want))
+	}
+	for i := range want {
+		if loaded[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, loaded[i], want[i])
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"missing down", []string{"000001_users.up.sql"}, "migration 1_users needs both an up and a down file"},
+		{"missing up", []string{"000001_users.down.sql"}, "needs both an up and a down file"},
+		{"two names", []string{"000001_users.up.sql", "000001_accounts.down.sql"}, "migration 1 has two names"},
+		{"version out of range", []string{"99999999999999999999_big.up.sql"}, "invalid migration version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1")}
+			}
+			_, err := Load(fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks the migrations shipped with the binaries
+func TestEmbeddedMigrations(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range loaded {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s, want version %d: versions must be consecutive", m.Version, m.Name, i+1)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int64
+		run     func(*Migrator) error
+		scripts []string
+		want    []int64
+	}{
+		{
+			name:    "up from scratch",
+			run:     func(m *Migrator) error { return m.Up(context.Background()) },
+			scripts: []string{"up 000001_users", "up 000002_profiles", "up 000010_teams"},
+			want:    []int64{1, 2, 10},
+		},
+		{
+			name:    "up applies pending only",
+			applied: []int64{1},
+			run:     func(m *Migrator) error { return m.Up(context.Background()) },
+			scripts: []string{"up 000002_profiles", "up 000010_teams"},
+			want:    []int64{1, 2, 10},
+		},
+		{
+			name:    "down one",
+			applied: []int64{1, 2, 10},
+			run:     func(m *Migrator) error { return m.Down(context.Background(), 1) },
+			scripts: []string{"down 000010_teams"},
+			want:    []int64{1, 2},
+		},
+		{
+			name:    "down two in reverse order",
+			applied: []int64{1, 2, 10},
+			run:     func(m *Migrator) error { return m.Down(context.Background(), 2) },
+			scripts: []string{"down 000010_teams", "down 000002_profiles"},
+			want:    []int64{1},
+		},
+		{
+			name:    "down more than applied",
+			applied: []int64{1, 2},
+			run:     func(m *Migrator) error { return m.Down(context.Background(), 5) },
+			scripts: []string{"down 000002_profiles", "down 000001_users"},
+			want:    nil,
+		},
+		{
+			name:    "down on an empty database",
+			run:     func(m *Migrator) error { return m.Down(context.Background(), 1) },
+			scripts: nil,
+			want:    nil,
+		},
+		{
+			name:    "to an older version",
+			applied: []int64{1, 2, 10},
+			run:     func(m *Migrator) error { return m.To(context.Background(), 1) },
+			scripts: []string{"down 000010_teams", "down 000002_profiles"},
+			want:    []int64{1},
+		},
+		{
+			name:    "to a newer version",
+			run:     func(m *Migrator) error { return m.To(context.Background(), 2) },
+			scripts: []string{"up 000001_users", "up 000002_profiles"},
+			want:    []int64{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(tt.applied...)
+			m, err := New(sql.OpenDB(db), testFS())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.run(m); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(db.scripts, "; ") != strings.Join(tt.scripts, "; ") {
+				t.Errorf("ran %q, want %q", db.scripts, tt.scripts)
+			}
+			if got := db.versions(); !equalVersions(got, tt.want) {
+				t.Errorf("applied versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratorUnknownVersion(t *testing.T) {
+	db := newFakeDB()
+	m, err := New(sql.OpenDB(db), testFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.To(context.Background(), 3); err == nil {
+		t.Error("migrating to an unknown version succeeded")
+	}
+	if len(db.scripts) != 0 {
+		t.Errorf("ran %q", db.scripts)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	db := newFakeDB(1, 2)
+	m, err := New(sql.OpenDB(db), testFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := m.Current(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != 2 || m.Latest() != 10 {
+		t.Errorf("current %d, latest %d, want 2 and 10", current, m.Latest())
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.Applied != (s.Version <= 2) {
+			t.Errorf("migration %d applied = %v", s.Version, s.Applied)
+		}
+	}
+}
+
+func equalVersions(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeDB is a database/sql driver that understands the statements of the
+// Migrator and records the migration scripts it runs
+type fakeDB struct {
+	mu          sync.Mutex
+	tableExists bool
+	applied     map[int64]bool
+	scripts     []string
+}
+
+func newFakeDB(applied ...int64) *fakeDB {
+	db := &fakeDB{applied: map[int64]bool{}, tableExists: len(applied) > 0}
+	for _, version := range applied {
+		db.applied[version] = true
+	}
+	return db
+}
+
+// versions returns the applied versions in order
+func (db *fakeDB) versions() []int64 {
+	var versions []int64
+	for v := int64(0); v <= 10; v++ {
+		if db.applied[v] {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	query = strings.TrimSpace(query)
+	switch {
+	case strings.Contains(query, "pg_advisory"):
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		db.tableExists = true
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		db.applied[args[0].Value.(int64)] = true
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		delete(db.applied, args[0].Value.(int64))
+	default:
+		db.scripts = append(db.scripts, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "to_regclass"):
+		return &fakeRows{columns: []string{"exists"}, rows: [][]driver.Value{{db.tableExists}}}, nil
+	case strings.Contains(query, "MAX(version)"):
+		var max int64
+		for v := range db.applied {
+			max = maxVersion(max, v)
+		}
+		return &fakeRows{columns: []string{"max"}, rows: [][]driver.Value{{max}}}, nil
+	case strings.Contains(query, "SELECT version, applied_at"):
+		rows := &fakeRows{columns: []string{"version", "applied_at"}}
+		for _, v := range db.versions() {
+			rows.rows = append(rows.rows, []driver.Value{v, time.Now()})
+		}
+		return rows, nil
+	case strings.Contains(query, "SELECT version FROM"):
+		rows := &fakeRows{columns: []string{"version"}}
+		for _, v := range db.versions() {
+			rows.rows = append(rows.rows, []driver.Value{v})
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+func maxVersion(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -3,10 +3,10 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
-	"log"
 
 	"github.com/google/uuid"
     "github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
 	"github.com/rizkyswandy/TeamSeekerBackend/types"
 )
 
@@ -23,15 +23,14 @@ func (p *PostgresDB) CreateUser(user *types.User) error {
         userID,
         user.Email,
         user.Password, 
-        "user",
+        types.RoleStudent,
     ).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
     if err != nil {
         if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-            return fmt.Errorf("email already exists")
+            return apperr.Conflict("email already exists")
         }
-        log.Printf("Database error creating user: %v", err)
-        return fmt.Errorf("error creating user: %v", err)
+        return fmt.Errorf("error creating user: %w", err)
     }
 
     return nil
@@ -55,12 +54,11 @@ func (p *PostgresDB) GetUserByEmail(email string) (types.User, error) {
     )
 
     if err == sql.ErrNoRows {
-        return types.User{}, fmt.Errorf("user not found")
+        return types.User{}, apperr.NotFound("user not found")
     }
 
     if err != nil {
-        log.Printf("Database error getting user by email: %v", err)
-        return types.User{}, fmt.Errorf("error retrieving user")
+        return types.User{}, fmt.Errorf("error getting user by email: %w", err)
     }
 
     return user, nil
@@ -71,7 +69,7 @@ func (p *PostgresDB) GetUserByID(id string) (types.User, error) {
 
     userID, err := uuid.Parse(id)
     if err != nil {
-        return types.User{}, fmt.Errorf("invalid user ID format")
+        return types.User{}, apperr.InvalidInput("invalid user ID format")
     }
 
     query := `
@@ -89,13 +87,68 @@ func (p *PostgresDB) GetUserByID(id string) (types.User, error) {
     )
 
     if err == sql.ErrNoRows {
-        return types.User{}, fmt.Errorf("user not found")
+        return types.User{}, apperr.NotFound("user not found")
     }
 
     if err != nil {
-        log.Printf("Database error getting user by ID: %v", err)
-        return types.User{}, fmt.Errorf("error retrieving user")
+        return types.User{}, fmt.Errorf("error getting user by ID: %w", err)
     }
 
     return user, nil
-}
\ No newline at end of file
+}
+
+func (p *PostgresDB) ListUsers() ([]types.User, error) {
+    query := `
+        SELECT id, email, role, created_at, updated_at
+        FROM users
+        ORDER BY created_at`
+
+    rows, err := p.db.Query(query)
+    if err != nil {
+        return nil, fmt.Errorf("error listing users: %w", err)
+    }
+    defer rows.Close()
+
+    users := []types.User{}
+    for rows.Next() {
+        var user types.User
+        if err := rows.Scan(&user.ID, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+            return nil, err
+        }
+        users = append(users, user)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    return users, nil
+}
+
+func (p *PostgresDB) UpdateUserRole(id string, role string) error {
+    userID, err := uuid.Parse(id)
+    if err != nil {
+        return apperr.InvalidInput("invalid user ID format")
+    }
+
+    query := `
+        UPDATE users
+        SET role = $1,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = $2`
+
+    result, err := p.db.Exec(query, role, userID)
+    if err != nil {
+        return fmt.Errorf("error updating role of user: %w", err)
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rowsAffected == 0 {
+        return apperr.NotFound("user not found")
+    }
+
+    return nil
+}
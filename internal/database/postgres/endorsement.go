@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+func (p *PostgresDB) EndorseSkill(profileID string, skill string, userID string) (int, error) {
+	id, err := uuid.Parse(profileID)
+	if err != nil {
+		return 0, apperr.InvalidInput("invalid ID format")
+	}
+
+	_, err = p.db.Exec(`
+		INSERT INTO skill_endorsements (profile_id, skill, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		id, skill, userID,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return 0, apperr.NotFound("profile not found")
+		}
+		return 0, fmt.Errorf("error endorsing skill: %w", err)
+	}
+
+	return countEndorsements(p.db, id, skill)
+}
+
+func (p *PostgresDB) RevokeEndorsement(profileID string, skill string, userID string) (int, error) {
+	id, err := uuid.Parse(profileID)
+	if err != nil {
+		return 0, apperr.InvalidInput("invalid ID format")
+	}
+
+	result, err := p.db.Exec(`
+		DELETE FROM skill_endorsements
+		WHERE profile_id = $1 AND skill = $2 AND user_id = $3`,
+		id, skill, userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error revoking endorsement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, apperr.NotFound("endorsement not found")
+	}
+
+	return countEndorsements(p.db, id, skill)
+}
+
+func countEndorsements(q querier, profileID uuid.UUID, skill string) (int, error) {
+	var count int
+	err := q.QueryRow(`
+		SELECT COUNT(*) FROM skill_endorsements
+		WHERE profile_id = $1 AND skill = $2`,
+		profileID, skill,
+	).Scan(&count)
+	return count, err
+}
+
+// retagEndorsements moves endorsements to the canonical name of their skill.
+// Endorsements a user already has under the canonical name are dropped.
+func retagEndorsements(tx *sql.Tx, canonical func(string) string) error {
+	rows, err := tx.Query(`SELECT DISTINCT skill FROM skill_endorsements`)
+	if err != nil {
+		return err
+	}
+
+	renames := map[string]string{}
+	for rows.Next() {
+		var skill string
+		if err := rows.Scan(&skill); err != nil {
+			rows.Close()
+			return err
+		}
+		if name := canonical(skill); name != skill {
+			renames[skill] = name
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for from, to := range renames {
+		_, err := tx.Exec(`
+			INSERT INTO skill_endorsements (profile_id, skill, user_id, created_at)
+			SELECT profile_id, $2, user_id, created_at
+			FROM skill_endorsements WHERE skill = $1
+			ON CONFLICT DO NOTHING`,
+			from, to,
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM skill_endorsements WHERE skill = $1`, from); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
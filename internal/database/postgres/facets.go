@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+// ProfileFacets counts the fields with few values in one query over grouping
+// sets, and skills and focus areas in one query each
+func (p *PostgresDB) ProfileFacets(filter api.SearchFilters, limit int) (api.Facets, error) {
+	where, params, _ := searchWhere(filter)
+	facets := api.NewFacets()
+
+	rows, err := p.db.Query(`
+		SELECT
+			CASE
+				WHEN GROUPING(faculty) = 0 THEN 'faculty'
+				WHEN GROUPING(field_of_study) = 0 THEN 'field_of_study'
+				WHEN GROUPING(semester) = 0 THEN 'semester'
+				ELSE 'availability'
+			END AS facet,
+			COALESCE(faculty, field_of_study, semester::text, COALESCE(is_available, false)::text) AS value,
+			COUNT(*)
+		FROM student_profiles
+		WHERE `+where+`
+		GROUP BY GROUPING SETS ((faculty), (field_of_study), (semester), (COALESCE(is_available, false)))
+		ORDER BY facet, semester, COUNT(*) DESC, value`,
+		params...,
+	)
+	if err != nil {
+		return api.Facets{}, fmt.Errorf("error counting facets: %w", err)
+	}
+	defer rows.Close()
+
+	lists := map[string]*[]api.FacetCount{
+		"faculty":        &facets.Faculty,
+		"field_of_study": &facets.FieldOfStudy,
+		"semester":       &facets.Semester,
+		"availability":   &facets.Availability,
+	}
+	for rows.Next() {
+		var facet string
+		var count api.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			return api.Facets{}, err
+		}
+		*lists[facet] = append(*lists[facet], count)
+	}
+	if err := rows.Err(); err != nil {
+		return api.Facets{}, err
+	}
+
+	if facets.Skills, err = p.topFacet("skills", where, params, limit); err != nil {
+		return api.Facets{}, err
+	}
+	if facets.Focus, err = p.topFacet("focus", where, params, limit); err != nil {
+		return api.Facets{}, err
+	}
+
+	return facets, nil
+}
+
+// topFacet counts the limit most common values of an array column
+func (p *PostgresDB) topFacet(column string, where string, params []interface{}, limit int) ([]api.FacetCount, error) {
+	query := fmt.Sprintf(`
+		SELECT value, COUNT(*)
+		FROM student_profiles CROSS JOIN LATERAL unnest(%s) AS value
+		WHERE %s
+		GROUP BY value
+		ORDER BY COUNT(*) DESC, value
+		LIMIT $%d`, column, where, len(params)+1)
+
+	rows, err := p.db.Query(query, append(params, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting facet: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []api.FacetCount{}
+	for rows.Next() {
+		var count api.FacetCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
@@ -2,14 +2,18 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
 )
 
+var logger = logging.New("postgres")
+
 type PostgresDB struct {
 	db *sql.DB
 }
@@ -27,16 +31,105 @@ func NewPostgresDB(connString string) (*PostgresDB, error) {
 	return &PostgresDB{db: db}, nil
 }
 
+// Close closes the connection pool, waiting for running queries to finish
+func (p *PostgresDB) Close() error {
+	return p.db.Close()
+}
+
+// DB exposes the connection pool, e.g. to check the schema version
+func (p *PostgresDB) DB() *sql.DB {
+	return p.db
+}
+
+// profileColumns selects a profile from the unaliased student_profiles
+// table, endorsements are only counted for skills the profile still lists
+const profileColumns = `id, user_id, name, email, faculty, field_of_study, semester, skills, focus, bio,
+			skill_levels, is_available, created_at, updated_at, version,
+			COALESCE((
+				SELECT jsonb_object_agg(counts.skill, counts.endorsements)
+				FROM (
+					SELECT e.skill, COUNT(*) AS endorsements
+					FROM skill_endorsements e
+					WHERE e.profile_id = student_profiles.id AND e.skill = ANY(student_profiles.skills)
+					GROUP BY e.skill
+				) counts
+			), '{}'::jsonb)`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProfile(row rowScanner) (api.StudentProfile, error) {
+	var profile api.StudentProfile
+	var userID sql.NullString
+	var skillLevels, endorsements []byte
+
+	err := row.Scan(
+		&profile.ID,
+		&userID,
+		&profile.Name,
+		&profile.Email,
+		&profile.Faculty,
+		&profile.FieldOfStudy,
+		&profile.Semester,
+		pq.Array(&profile.Skills),
+		pq.Array(&profile.Focus),
+		&profile.Bio,
+		&skillLevels,
+		&profile.IsAvailable,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+		&profile.Version,
+		&endorsements,
+	)
+	if err != nil {
+		return api.StudentProfile{}, err
+	}
+	profile.UserID = userID.String
+
+	if err := json.Unmarshal(skillLevels, &profile.SkillLevels); err != nil {
+		return api.StudentProfile{}, err
+	}
+	if err := json.Unmarshal(endorsements, &profile.Endorsements); err != nil {
+		return api.StudentProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// skillLevelsJSON encodes the skill levels of a profile for the JSONB column
+func skillLevelsJSON(levels []api.SkillLevel) ([]byte, error) {
+	if levels == nil {
+		levels = []api.SkillLevel{}
+	}
+	return json.Marshal(levels)
+}
+
+// nullableUUID maps an empty owner ID to NULL for profiles without an owner
+func nullableUUID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
 // Creating profile
 func (p *PostgresDB) CreateProfile(profile *api.StudentProfile) error {
+    skillLevels, err := skillLevelsJSON(profile.SkillLevels)
+    if err != nil {
+        return err
+    }
+
     query := `
         INSERT INTO student_profiles 
-        (name, email, faculty, field_of_study, semester, skills, focus, is_available)
-        VALUES ($1, $2, $3, $4, $5, $6::text[], $7::text[], $8)
-        RETURNING id, created_at, updated_at`
+        (user_id, name, email, faculty, field_of_study, semester, skills, focus, bio, skill_levels, is_available)
+        VALUES ($1, $2, $3, $4, $5, $6, $7::text[], $8::text[], $9, $10, $11)
+        RETURNING id, created_at, updated_at, version`
 
-    err := p.db.QueryRow(
+    err = p.db.QueryRow(
         query,
+        nullableUUID(profile.UserID),
         profile.Name,
         profile.Email,
         profile.Faculty,
@@ -44,60 +137,81 @@ func (p *PostgresDB) CreateProfile(profile *api.StudentProfile) error {
         profile.Semester,
         pq.Array(profile.Skills),
         pq.Array(profile.Focus),
+        profile.Bio,
+        skillLevels,
         profile.IsAvailable,
-    ).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+    ).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt, &profile.Version)
 
     if err != nil {
-        log.Printf("Database error: %v", err)
-        return err
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+            if pqErr.Constraint == "student_profiles_user_id_key" {
+                return apperr.Conflict("profile already exists")
+            }
+            return apperr.Conflict("email already exists")
+        }
+        return fmt.Errorf("error creating profile: %w", err)
     }
 
+    // A new profile has no endorsements yet
+    profile.Endorsements = map[string]int{}
     return nil
 }
 
 func (p *PostgresDB) GetProfile(id string) (api.StudentProfile, error) {
-	var profile api.StudentProfile
-
 	query := `
-		SELECT id, name, email, faculty, field_of_study, semester, skills, focus, 
-			is_available, created_at, updated_at
+		SELECT ` + profileColumns + `
 		FROM student_profiles WHERE id = $1`
 
 	profileID, err := uuid.Parse(id)
 	if err != nil {
-		return api.StudentProfile{}, fmt.Errorf("invalid ID format")
+		return api.StudentProfile{}, apperr.InvalidInput("invalid ID format")
 	}
 
-	err = p.db.QueryRow(query, profileID).Scan(
-		&profile.ID,
-		&profile.Name,
-		&profile.Email,
-		&profile.Faculty,
-		&profile.FieldOfStudy,
-		&profile.Semester,
-		pq.Array(&profile.Skills),
-		pq.Array(&profile.Focus),
-		&profile.IsAvailable,
-		&profile.CreatedAt,
-		&profile.UpdatedAt,
-	)
+	profile, err := scanProfile(p.db.QueryRow(query, profileID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Debug("No profile found", "profile_id", id)
+			return api.StudentProfile{}, apperr.NotFound("profile not found")
+		}
+		return api.StudentProfile{}, fmt.Errorf("error getting profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+func (p *PostgresDB) GetProfileByUserID(userID string) (api.StudentProfile, error) {
+	query := `
+		SELECT ` + profileColumns + `
+		FROM student_profiles WHERE user_id = $1`
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return api.StudentProfile{}, apperr.InvalidInput("invalid user ID format")
+	}
 
+	profile, err := scanProfile(p.db.QueryRow(query, ownerID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("No profile found with id: %s", id)
-			return api.StudentProfile{}, fmt.Errorf("profile not found")
+			return api.StudentProfile{}, apperr.NotFound("profile not found")
 		}
-		log.Printf("Database error getting profile %s: %v", id, err)
-		return api.StudentProfile{}, err
+		return api.StudentProfile{}, fmt.Errorf("error getting profile of user: %w", err)
 	}
 
 	return profile, nil
 }
 
-func (p *PostgresDB) UpdateProfile(id string, profile *api.StudentProfile) error {
+// UpdateProfile overwrites the editable fields and bumps the version. A
+// non-zero version makes the update conditional on the stored version.
+// profile is refreshed from the updated row.
+func (p *PostgresDB) UpdateProfile(id string, profile *api.StudentProfile, version int) error {
 	profileID, err := uuid.Parse(id)
 	if err != nil {
-		return fmt.Errorf("invalid ID format")
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	skillLevels, err := skillLevelsJSON(profile.SkillLevels)
+	if err != nil {
+		return err
 	}
 
 	query := `
@@ -109,11 +223,15 @@ func (p *PostgresDB) UpdateProfile(id string, profile *api.StudentProfile) error
 			semester = $5,
 			skills = $6,
 			focus = $7,
-			is_available = $8,
+			bio = $8,
+			skill_levels = $9,
+			is_available = $10,
+			version = version + 1,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $9`
+		WHERE id = $11 AND ($12::int = 0 OR version = $12::int)
+		RETURNING ` + profileColumns
 
-	result, err := p.db.Exec(
+	updated, err := scanProfile(p.db.QueryRow(
 		query,
 		profile.Name,
 		profile.Email,
@@ -122,24 +240,32 @@ func (p *PostgresDB) UpdateProfile(id string, profile *api.StudentProfile) error
 		profile.Semester,
 		pq.Array(profile.Skills),
 		pq.Array(profile.Focus),
+		profile.Bio,
+		skillLevels,
 		profile.IsAvailable,
 		profileID,
-	)
-
-	if err != nil {
-		log.Printf("Database error updating profile %s: %v", id, err)
-		return err
+		version,
+	))
+
+	if err == sql.ErrNoRows {
+		// Either the profile is gone or someone else updated it first
+		var exists bool
+		if err := p.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM student_profiles WHERE id = $1)`, profileID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return apperr.NotFound("profile not found")
+		}
+		return apperr.PreconditionFailed("profile was modified since version %d", version)
 	}
-
-	// Check if any row was actually updated
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
-	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("profile not found")
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return apperr.Conflict("email already exists")
+		}
+		return fmt.Errorf("error updating profile: %w", err)
 	}
 
+	*profile = updated
 	return nil
 }
 
@@ -149,7 +275,7 @@ func (p *PostgresDB) DeleteProfile(id string) error {
 
 	profileID, err := uuid.Parse(id)
 	if err != nil {
-		return fmt.Errorf("invalid ID format")
+		return apperr.InvalidInput("invalid ID format")
 	}
 
 	result, err := p.db.Exec(query, profileID)
@@ -163,108 +289,258 @@ func (p *PostgresDB) DeleteProfile(id string) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("profile with id %s not found", id)
+		return apperr.NotFound("profile not found")
 	}
 
 	return nil
 }
 
-func (p *PostgresDB) GetAllProfiles() ([]api.StudentProfile, error) {
+func (p *PostgresDB) GetAllProfiles(opts api.ListOptions) (api.ProfilePage, error) {
+	return p.listProfiles("1=1", nil, opts, nil)
+}
+
+// sortColumns maps the API sort keys to columns and the type their cursor
+// value has to be cast to
+var sortColumns = map[string]struct{ column, cast string }{
+	api.SortCreatedAt: {"created_at", "timestamptz"},
+	api.SortName:      {"name", "text"},
+	api.SortSemester:  {"semester", "integer"},
+	// Only available with a text search, see listProfiles
+	api.SortRelevance: {"rank", "real"},
+}
+
+// pageClause appends the keyset condition, ordering and limit of a page to
+// a query whose WHERE clause already uses params. prefix qualifies the
+// columns, e.g. "t." when the table is aliased.
+func pageClause(prefix string, params []interface{}, opts api.ListOptions) (string, []interface{}, error) {
+	sort, ok := sortColumns[opts.Sort]
+	if !ok {
+		sort = sortColumns[api.SortCreatedAt]
+	}
+	column := prefix + sort.column
+
+	direction, comparison := "ASC", ">"
+	if opts.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	var clause string
+	paramCount := len(params) + 1
+
+	if opts.After != nil {
+		afterID, err := uuid.Parse(opts.After.ID)
+		if err != nil {
+			return "", nil, apperr.InvalidInput("invalid cursor")
+		}
+		clause += fmt.Sprintf(" AND (%s, %sid) %s ($%d::%s, $%d)",
+			column, prefix, comparison, paramCount, sort.cast, paramCount+1)
+		params = append(params, opts.After.Value, afterID)
+		paramCount += 2
+	}
+
+	clause += fmt.Sprintf(" ORDER BY %s %s, %sid %s LIMIT $%d OFFSET $%d",
+		column, direction, prefix, direction, paramCount, paramCount+1)
+	params = append(params, opts.Limit+1, opts.Offset)
+
+	return clause, params, nil
+}
+
+// listProfiles returns one page of the profiles matching where, which may
+// reference params as $1..$n. Ties on the sort column are broken by id. With
+// a search the profiles are ranked and highlighted.
+func (p *PostgresDB) listProfiles(where string, params []interface{}, opts api.ListOptions, search *textSearch) (api.ProfilePage, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM student_profiles WHERE ` + where
+	if err := p.db.QueryRow(countQuery, params...).Scan(&total); err != nil {
+		return api.ProfilePage{}, fmt.Errorf("error counting profiles: %w", err)
+	}
+
 	query := `
-		SELECT *
-		FROM student_profiles`
+		SELECT ` + profileColumns + `
+		FROM student_profiles
+		WHERE ` + where
+	scan := scanProfile
+
+	if search != nil {
+		// The subquery is named like the table so that profileColumns works
+		// on it and rank can be paginated on like any other column
+		query = `
+		SELECT ` + profileColumns + `, ` + search.highlightColumns() + `
+		FROM (
+			SELECT *, ` + search.rank() + ` AS rank
+			FROM student_profiles
+			WHERE ` + where + `
+		) student_profiles
+		WHERE TRUE`
+		scan = scanSearchResult
+	}
 
-	rows, err := p.db.Query(query)
+	pageSQL, params, err := pageClause("", params, opts)
 	if err != nil {
-		return nil, err
+		return api.ProfilePage{}, err
+	}
+	query += pageSQL
+
+	// The query is built from the filters, log it to see what ran
+	logger.Debug("Listing profiles", "query", query)
+	rows, err := p.db.Query(query, params...)
+	if err != nil {
+		return api.ProfilePage{}, fmt.Errorf("error listing profiles: %w", err)
 	}
 	defer rows.Close()
 
 	var profiles []api.StudentProfile
 
 	for rows.Next() {
-		var profile api.StudentProfile
-
-		err := rows.Scan(
-			&profile.ID,
-			&profile.Name,
-			&profile.Email,
-			&profile.Faculty,
-			&profile.FieldOfStudy,
-			&profile.Semester,
-			pq.Array(&profile.Skills),
-			pq.Array(&profile.Focus),
-			&profile.IsAvailable,
-			&profile.CreatedAt,
-			&profile.UpdatedAt,
-		)
+		profile, err := scan(rows)
 		if err != nil {
-			return nil, err
+			return api.ProfilePage{}, err
 		}
 		profiles = append(profiles, profile)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return api.ProfilePage{}, err
 	}
 
-	return profiles, nil
+	return api.NewProfilePage(opts, profiles, total), nil
 }
 
-func (p *PostgresDB) SearchProfiles(filter api.SearchFilters) ([]api.StudentProfile, error) {
-	query := `
-		SELECT id, name, email, faculty, field_of_study, semester, skills, focus, is_available, 
-			   created_at, updated_at
-		FROM student_profiles
-		WHERE 1=1`
+func (p *PostgresDB) SearchProfiles(filter api.SearchFilters, opts api.ListOptions) (api.ProfilePage, error) {
+	where, params, search := searchWhere(filter)
+	return p.listProfiles(where, params, opts, search)
+}
+
+// searchWhere builds the WHERE clause and parameters of the profiles
+// matching filter, and the text search when filter has a q
+func searchWhere(filter api.SearchFilters) (string, []interface{}, *textSearch) {
+	where := "1=1"
 
 	var params []interface{}
-	paramCount := 1
+
+	search, params := newTextSearch(filter.Query, params)
+	if search != nil {
+		where += " AND " + search.where()
+	}
+	paramCount := len(params) + 1
 
 	if filter.Faculty != "" {
-		query += fmt.Sprintf(" AND faculty = $%d", paramCount)
+		where += fmt.Sprintf(" AND faculty = $%d", paramCount)
 		params = append(params, filter.Faculty)
 		paramCount++
 	}
 
+	if filter.FieldOfStudy != "" {
+		where += fmt.Sprintf(" AND field_of_study = $%d", paramCount)
+		params = append(params, filter.FieldOfStudy)
+		paramCount++
+	}
+
 	if len(filter.Skills) > 0 {
-		query += fmt.Sprintf(" AND skills && $%d", paramCount)
+		where += fmt.Sprintf(" AND skills %s $%d", arrayOperator(filter.SkillsMatch), paramCount)
 		params = append(params, pq.Array(filter.Skills))
 		paramCount++
 	}
 
+	if filter.MinProficiency != "" && len(filter.Skills) > 0 {
+		// Compare against every level at or above the minimum
+		levels := api.ProficiencyLevels[api.ProficiencyRank(filter.MinProficiency)-1:]
+		proficient := fmt.Sprintf(`
+			SELECT COUNT(DISTINCT l->>'skill')
+			FROM jsonb_array_elements(skill_levels) l
+			WHERE l->>'skill' = ANY($%d) AND l->>'level' = ANY($%d)`, paramCount, paramCount+1)
+		if filter.SkillsMatch == api.MatchAll {
+			where += fmt.Sprintf(" AND (%s) = cardinality($%d::text[])", proficient, paramCount)
+		} else {
+			where += fmt.Sprintf(" AND (%s) > 0", proficient)
+		}
+		params = append(params, pq.Array(filter.Skills), pq.Array(levels))
+		paramCount += 2
+	}
+
+	if len(filter.ExcludeSkills) > 0 {
+		where += fmt.Sprintf(" AND NOT (skills && $%d)", paramCount)
+		params = append(params, pq.Array(filter.ExcludeSkills))
+		paramCount++
+	}
+
 	if len(filter.Focus) > 0 {
-		query += fmt.Sprintf(" AND focus && $%d", paramCount)
+		where += fmt.Sprintf(" AND focus %s $%d", arrayOperator(filter.FocusMatch), paramCount)
 		params = append(params, pq.Array(filter.Focus))
 		paramCount++
 	}
 
-	query += fmt.Sprintf(" AND is_available = $%d", paramCount)
-	params = append(params, filter.Availability)
+	if len(filter.ExcludeFocus) > 0 {
+		where += fmt.Sprintf(" AND NOT (focus && $%d)", paramCount)
+		params = append(params, pq.Array(filter.ExcludeFocus))
+		paramCount++
+	}
+
+	if filter.SemesterMin > 0 {
+		where += fmt.Sprintf(" AND semester >= $%d", paramCount)
+		params = append(params, filter.SemesterMin)
+		paramCount++
+	}
 
-	rows, err := p.db.Query(query, params...)
+	if filter.SemesterMax > 0 {
+		where += fmt.Sprintf(" AND semester <= $%d", paramCount)
+		params = append(params, filter.SemesterMax)
+		paramCount++
+	}
+
+	if filter.Availability != nil {
+		where += fmt.Sprintf(" AND is_available = $%d", paramCount)
+		params = append(params, *filter.Availability)
+		paramCount++
+	}
+
+	return where, params, search
+}
+
+func (p *PostgresDB) FindMatchCandidates(q api.MatchQuery, limit int) ([]api.StudentProfile, error) {
+	available := ""
+	if q.OnlyAvailable {
+		available = " AND is_available = true"
+	}
+
+	// Score every profile sharing a term and pick the best by their ids
+	// first, so that endorsements are only counted for the profiles that
+	// are returned
+	query := `
+		WITH candidates AS (
+			SELECT id, updated_at,
+				COALESCE((
+					SELECT SUM(t.score) FROM unnest($1::text[], $2::float8[]) AS t(name, score)
+					WHERE lower(t.name) IN (SELECT lower(s) FROM unnest(skills) s)
+				), 0) +
+				COALESCE((
+					SELECT SUM(t.score) FROM unnest($3::text[], $4::float8[]) AS t(name, score)
+					WHERE lower(t.name) IN (SELECT lower(f) FROM unnest(focus) f)
+				), 0) +
+				$5 * GREATEST(0, 1 - abs(semester - $6)::float8 / $7) +
+				CASE WHEN COALESCE(is_available, false) THEN $8 ELSE 0 END AS score
+			FROM student_profiles
+			WHERE (skills && $1 OR focus && $3)
+				AND user_id IS DISTINCT FROM NULLIF($9, '')::uuid` + available + `
+			ORDER BY score DESC, updated_at DESC, id
+			LIMIT $10
+		)
+		SELECT ` + profileColumns + `
+		FROM student_profiles
+		JOIN candidates USING (id)
+		ORDER BY candidates.score DESC, candidates.updated_at DESC, id`
+
+	rows, err := p.db.Query(query, pq.Array(q.Skills), pq.Array(q.SkillScores), pq.Array(q.Focus), pq.Array(q.FocusScores),
+		q.SemesterScore, q.TargetSemester, q.SemesterRange, q.AvailableScore, q.ExcludeUserID, limit)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error finding match candidates: %w", err)
 	}
 	defer rows.Close()
 
 	var profiles []api.StudentProfile
 
 	for rows.Next() {
-		var profile api.StudentProfile
-		err := rows.Scan(
-			&profile.ID,
-			&profile.Name,
-			&profile.Email,
-			&profile.Faculty,
-			&profile.FieldOfStudy,
-			&profile.Semester,
-			pq.Array(&profile.Skills),
-			pq.Array(&profile.Focus),
-			&profile.IsAvailable,
-			&profile.CreatedAt,
-			&profile.UpdatedAt,
-		)
+		profile, err := scanProfile(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -276,4 +552,12 @@ func (p *PostgresDB) SearchProfiles(filter api.SearchFilters) ([]api.StudentProf
 	}
 
 	return profiles, nil
-}
\ No newline at end of file
+}
+
+// arrayOperator is overlap for "any" matching and containment for "all"
+func arrayOperator(match string) string {
+	if match == api.MatchAll {
+		return "@>"
+	}
+	return "&&"
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+)
+
+// textSearch is the q of a profile search. Every word is matched as a
+// prefix against search_vector; profiles that don't match because of a typo
+// are still found through the trigram similarity of search_text.
+type textSearch struct {
+	// tsquery and text are the positions of the two query parameters
+	tsquery, text int
+}
+
+// newTextSearch appends the parameters of q to params, or returns nil when
+// q has no words to search for
+func newTextSearch(q string, params []interface{}) (*textSearch, []interface{}) {
+	words := api.QueryWords(q)
+	if len(words) == 0 {
+		return nil, params
+	}
+
+	prefixes := make([]string, len(words))
+	for i, word := range words {
+		prefixes[i] = word + ":*"
+	}
+
+	search := &textSearch{tsquery: len(params) + 1, text: len(params) + 2}
+	return search, append(params, strings.Join(prefixes, " & "), strings.Join(words, " "))
+}
+
+func (s *textSearch) query() string {
+	return fmt.Sprintf("to_tsquery('english', $%d)", s.tsquery)
+}
+
+func (s *textSearch) where() string {
+	return fmt.Sprintf("(search_vector @@ %s OR search_text %%> $%d)", s.query(), s.text)
+}
+
+// rank puts full-text matches, weighted by field, before typo matches
+func (s *textSearch) rank() string {
+	return fmt.Sprintf("(ts_rank(search_vector, %s) + word_similarity($%d, search_text))::real", s.query(), s.text)
+}
+
+const (
+	headlineOptions    = `StartSel="<mark>", StopSel="</mark>", HighlightAll=true`
+	bioHeadlineOptions = `StartSel="<mark>", StopSel="</mark>", MaxFragments=2, MaxWords=25, MinWords=10, FragmentDelimiter=" … "`
+)
+
+// highlightFields are the fields the highlights of a match are taken from,
+// in the order of highlightColumns
+var highlightFields = []string{"name", "field_of_study", "skills", "focus", "bio"}
+
+// highlightColumns selects the rank and highlighted fields of a profile,
+// from a subquery that has a rank column
+func (s *textSearch) highlightColumns() string {
+	headline := func(text, options string) string {
+		return fmt.Sprintf("ts_headline('english', %s, %s, '%s')", text, s.query(), options)
+	}
+	return strings.Join([]string{
+		"rank",
+		headline("name", headlineOptions),
+		headline("field_of_study", headlineOptions),
+		headline("array_to_string(skills, ', ')", headlineOptions),
+		headline("array_to_string(focus, ', ')", headlineOptions),
+		headline("bio", bioHeadlineOptions),
+	}, ", ")
+}
+
+// searchScanner scans the columns of highlightColumns after the profile
+type searchScanner struct {
+	rowScanner
+	match      api.SearchMatch
+	highlights []string
+}
+
+func newSearchScanner(row rowScanner) *searchScanner {
+	return &searchScanner{rowScanner: row, highlights: make([]string, len(highlightFields))}
+}
+
+func (s *searchScanner) Scan(dest ...interface{}) error {
+	dest = append(dest, &s.match.Rank)
+	for i := range s.highlights {
+		dest = append(dest, &s.highlights[i])
+	}
+	return s.rowScanner.Scan(dest...)
+}
+
+// scanSearchResult scans a profile followed by highlightColumns. Only
+// fields that contain a matched word are highlighted.
+func scanSearchResult(row rowScanner) (api.StudentProfile, error) {
+	scanner := newSearchScanner(row)
+	profile, err := scanProfile(scanner)
+	if err != nil {
+		return api.StudentProfile{}, err
+	}
+
+	match := scanner.match
+	match.Highlights = map[string]string{}
+	for i, field := range highlightFields {
+		if strings.Contains(scanner.highlights[i], api.HighlightStart) {
+			match.Highlights[field] = api.Highlight(scanner.highlights[i])
+		}
+	}
+	profile.Match = &match
+
+	return profile, nil
+}
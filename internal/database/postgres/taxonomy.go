@@ -0,0 +1,546 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+const termColumns = `t.id, t.kind, t.name, t.category,
+			ARRAY(SELECT a.alias FROM taxonomy_aliases a WHERE a.term_id = t.id AND a.relation = 'alias' ORDER BY lower(a.alias)),
+			ARRAY(SELECT a.alias FROM taxonomy_aliases a WHERE a.term_id = t.id AND a.relation = 'synonym' ORDER BY lower(a.alias)),
+			t.created_at, t.updated_at`
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func scanTerm(row rowScanner) (api.Term, error) {
+	var term api.Term
+
+	err := row.Scan(
+		&term.ID,
+		&term.Kind,
+		&term.Name,
+		&term.Category,
+		pq.Array(&term.Aliases),
+		pq.Array(&term.Synonyms),
+		&term.CreatedAt,
+		&term.UpdatedAt,
+	)
+	if err != nil {
+		return api.Term{}, err
+	}
+	if term.Aliases == nil {
+		term.Aliases = []string{}
+	}
+	if term.Synonyms == nil {
+		term.Synonyms = []string{}
+	}
+
+	return term, nil
+}
+
+func getTerm(q querier, id uuid.UUID) (api.Term, error) {
+	term, err := scanTerm(q.QueryRow(`SELECT `+termColumns+` FROM taxonomy_terms t WHERE t.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return api.Term{}, apperr.NotFound("term not found")
+	}
+	return term, err
+}
+
+// checkTermNames makes sure no other term of the same kind uses the name,
+// aliases or synonyms of term. The unique indexes only cover names and
+// aliases separately.
+func checkTermNames(q querier, term *api.Term, id uuid.UUID) error {
+	names := []string{strings.ToLower(term.Name)}
+	for _, alias := range append(append([]string{}, term.Aliases...), term.Synonyms...) {
+		names = append(names, strings.ToLower(alias))
+	}
+
+	var taken string
+	err := q.QueryRow(`
+		SELECT name FROM taxonomy_terms
+		WHERE kind = $1 AND id <> $2 AND lower(name) = ANY($3)
+		UNION ALL
+		SELECT alias FROM taxonomy_aliases
+		WHERE kind = $1 AND term_id <> $2 AND lower(alias) = ANY($3)
+		LIMIT 1`,
+		term.Kind,
+		id,
+		pq.Array(names),
+	).Scan(&taken)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return apperr.Conflict("%q is already used by another %s", taken, term.Kind)
+}
+
+func insertAliases(tx *sql.Tx, id uuid.UUID, term *api.Term) error {
+	insert := func(alias, relation string) error {
+		_, err := tx.Exec(`
+			INSERT INTO taxonomy_aliases (term_id, kind, alias, relation)
+			VALUES ($1, $2, $3, $4)`,
+			id,
+			term.Kind,
+			alias,
+			relation,
+		)
+		return err
+	}
+
+	for _, alias := range term.Aliases {
+		if err := insert(alias, "alias"); err != nil {
+			return err
+		}
+	}
+	for _, synonym := range term.Synonyms {
+		if err := insert(synonym, "synonym"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func termError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return apperr.Conflict("name or alias is already used by another term")
+	}
+	return err
+}
+
+func (p *PostgresDB) CreateTerm(term *api.Term) error {
+	termID := uuid.New()
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTermNames(tx, term, termID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO taxonomy_terms (id, kind, name, category)
+		VALUES ($1, $2, $3, $4)`,
+		termID,
+		term.Kind,
+		term.Name,
+		term.Category,
+	)
+	if err != nil {
+		return termError(err)
+	}
+
+	if err := insertAliases(tx, termID, term); err != nil {
+		return termError(err)
+	}
+
+	created, err := getTerm(tx, termID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*term = created
+	return nil
+}
+
+func (p *PostgresDB) GetTerm(id string) (api.Term, error) {
+	termID, err := uuid.Parse(id)
+	if err != nil {
+		return api.Term{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	return getTerm(p.db, termID)
+}
+
+func (p *PostgresDB) UpdateTerm(id string, term *api.Term) error {
+	termID, err := uuid.Parse(id)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTermNames(tx, term, termID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE taxonomy_terms
+		SET name = $1, category = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3`,
+		term.Name,
+		term.Category,
+		termID,
+	)
+	if err != nil {
+		return termError(err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return apperr.NotFound("term not found")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM taxonomy_aliases WHERE term_id = $1`, termID); err != nil {
+		return err
+	}
+	if err := insertAliases(tx, termID, term); err != nil {
+		return termError(err)
+	}
+
+	updated, err := getTerm(tx, termID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*term = updated
+	return nil
+}
+
+func (p *PostgresDB) DeleteTerm(id string) error {
+	termID, err := uuid.Parse(id)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	result, err := p.db.Exec(`DELETE FROM taxonomy_terms WHERE id = $1`, termID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("term not found")
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) SearchTerms(kind string, prefix string, limit int) ([]api.Term, error) {
+	prefix = strings.ToLower(prefix)
+	pattern := likeEscaper.Replace(prefix) + "%"
+
+	var limitParam interface{}
+	if limit > 0 {
+		limitParam = limit
+	}
+
+	rows, err := p.db.Query(`
+		SELECT `+termColumns+`
+		FROM taxonomy_terms t
+		WHERE t.kind = $1
+			AND ($2 = '' OR lower(t.name) LIKE $3 OR EXISTS (
+				SELECT 1 FROM taxonomy_aliases a WHERE a.term_id = t.id AND lower(a.alias) LIKE $3
+			))
+		ORDER BY lower(t.name) = $2 DESC, lower(t.name) LIKE $3 DESC, lower(t.name)
+		LIMIT $4`,
+		kind,
+		prefix,
+		pattern,
+		limitParam,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error searching terms: %w", err)
+	}
+	defer rows.Close()
+
+	terms := []api.Term{}
+	for rows.Next() {
+		term, err := scanTerm(rows)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards of user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (p *PostgresDB) TermLookup(kind string) (api.TermLookup, error) {
+	return termLookup(p.db, kind)
+}
+
+func termLookup(q querier, kind string) (api.TermLookup, error) {
+	rows, err := q.Query(`
+		SELECT lower(name), name FROM taxonomy_terms WHERE kind = $1
+		UNION ALL
+		SELECT lower(a.alias), t.name
+		FROM taxonomy_aliases a
+		JOIN taxonomy_terms t ON t.id = a.term_id
+		WHERE a.kind = $1`,
+		kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lookup := api.TermLookup{}
+	for rows.Next() {
+		var key, name string
+		if err := rows.Scan(&key, &name); err != nil {
+			return nil, err
+		}
+		lookup[key] = name
+	}
+
+	return lookup, rows.Err()
+}
+
+func (p *PostgresDB) MergeTerms(sourceID string, targetID string) (api.MergeResult, error) {
+	sourceUUID, err := uuid.Parse(sourceID)
+	if err != nil {
+		return api.MergeResult{}, apperr.InvalidInput("invalid ID format")
+	}
+	targetUUID, err := uuid.Parse(targetID)
+	if err != nil {
+		return api.MergeResult{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+	defer tx.Rollback()
+
+	source, err := getTerm(tx, sourceUUID)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+	target, err := getTerm(tx, targetUUID)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+	if source.Kind != target.Kind {
+		return api.MergeResult{}, apperr.InvalidInput("can't merge a %s into a %s", source.Kind, target.Kind)
+	}
+
+	// Move the aliases before the delete cascades to them
+	_, err = tx.Exec(`UPDATE taxonomy_aliases SET term_id = $1 WHERE term_id = $2`, targetUUID, sourceUUID)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+	if _, err := tx.Exec(`DELETE FROM taxonomy_terms WHERE id = $1`, sourceUUID); err != nil {
+		return api.MergeResult{}, err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO taxonomy_aliases (term_id, kind, alias, relation)
+		VALUES ($1, $2, $3, 'alias')`,
+		targetUUID,
+		source.Kind,
+		source.Name,
+	)
+	if err != nil {
+		return api.MergeResult{}, termError(err)
+	}
+	_, err = tx.Exec(`UPDATE taxonomy_terms SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, targetUUID)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+
+	retagged, err := retag(tx)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+
+	merged, err := getTerm(tx, targetUUID)
+	if err != nil {
+		return api.MergeResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.MergeResult{}, err
+	}
+
+	return api.MergeResult{Term: merged, RetagResult: retagged}, nil
+}
+
+func (p *PostgresDB) Retag() (api.RetagResult, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+	defer tx.Rollback()
+
+	retagged, err := retag(tx)
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+
+	return retagged, tx.Commit()
+}
+
+// retag applies the current taxonomy to every profile and team
+func retag(tx *sql.Tx) (api.RetagResult, error) {
+	skills, err := termLookup(tx, api.TermSkill)
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+	focus, err := termLookup(tx, api.TermFocus)
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+
+	profiles, err := retagProfiles(tx, skills, focus)
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+	teams, err := retagTeams(tx, skills, focus)
+	if err != nil {
+		return api.RetagResult{}, err
+	}
+
+	return api.RetagResult{Retagged: profiles, RetaggedTeams: teams}, nil
+}
+
+// retagProfiles rewrites skills, skill levels and focus of every profile
+// that uses a non-canonical name and moves endorsements along. Changed
+// profiles get a new version so that stale ETags are rejected.
+func retagProfiles(tx *sql.Tx, skills, focus api.TermLookup) (int, error) {
+	type retag struct {
+		id            string
+		skills, focus []string
+		skillLevels   []byte
+	}
+
+	rows, err := tx.Query(`SELECT id, skills, focus, skill_levels FROM student_profiles FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed []retag
+	for rows.Next() {
+		var id string
+		var profileSkills, profileFocus []string
+		var rawLevels []byte
+		if err := rows.Scan(&id, pq.Array(&profileSkills), pq.Array(&profileFocus), &rawLevels); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		var levels []api.SkillLevel
+		if err := json.Unmarshal(rawLevels, &levels); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		nextLevels := skills.CanonicalLevels(levels)
+
+		next := retag{id: id, skills: skills.Canonical(profileSkills), focus: focus.Canonical(profileFocus)}
+		if !slices.Equal(next.skills, profileSkills) || !slices.Equal(next.focus, profileFocus) || !slices.Equal(nextLevels, levels) {
+			if next.skillLevels, err = skillLevelsJSON(nextLevels); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			changed = append(changed, next)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range changed {
+		_, err := tx.Exec(`
+			UPDATE student_profiles
+			SET skills = $1, focus = $2, skill_levels = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4`,
+			pq.Array(r.skills),
+			pq.Array(r.focus),
+			r.skillLevels,
+			r.id,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error re-tagging profile %s: %w", r.id, err)
+		}
+	}
+
+	if err := retagEndorsements(tx, skills.Name); err != nil {
+		return 0, fmt.Errorf("error re-tagging endorsements: %w", err)
+	}
+
+	return len(changed), nil
+}
+
+// retagTeams rewrites the required skills and focus of every team that uses
+// a non-canonical name
+func retagTeams(tx *sql.Tx, skills, focus api.TermLookup) (int, error) {
+	type retag struct {
+		id            string
+		skills, focus []string
+	}
+
+	rows, err := tx.Query(`SELECT id, required_skills, focus FROM teams FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed []retag
+	for rows.Next() {
+		var id string
+		var teamSkills, teamFocus []string
+		if err := rows.Scan(&id, pq.Array(&teamSkills), pq.Array(&teamFocus)); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		next := retag{id: id, skills: skills.Canonical(teamSkills), focus: focus.Canonical(teamFocus)}
+		if !slices.Equal(next.skills, teamSkills) || !slices.Equal(next.focus, teamFocus) {
+			changed = append(changed, next)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range changed {
+		_, err := tx.Exec(`
+			UPDATE teams
+			SET required_skills = $1, focus = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $3`,
+			pq.Array(r.skills),
+			pq.Array(r.focus),
+			r.id,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error re-tagging team %s: %w", r.id, err)
+		}
+	}
+
+	return len(changed), nil
+}
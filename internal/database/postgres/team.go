@@ -0,0 +1,357 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+const teamColumns = `t.id, t.name, t.description, t.owner_id, t.required_skills, t.focus, t.max_size,
+			(SELECT COUNT(*) FROM team_members m WHERE m.team_id = t.id), t.status, t.created_at, t.updated_at`
+
+func scanTeam(row rowScanner) (api.Team, error) {
+	var team api.Team
+
+	err := row.Scan(
+		&team.ID,
+		&team.Name,
+		&team.Description,
+		&team.OwnerID,
+		pq.Array(&team.RequiredSkills),
+		pq.Array(&team.Focus),
+		&team.MaxSize,
+		&team.MemberCount,
+		&team.Status,
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	if err != nil {
+		return api.Team{}, err
+	}
+
+	return team, nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// refreshTeamStatus marks a team full or open again after its members or
+// max size changed. Closed teams stay closed.
+func refreshTeamStatus(db execer, teamID interface{}) error {
+	_, err := db.Exec(`
+		UPDATE teams
+		SET status = CASE
+				WHEN status = 'closed' THEN 'closed'
+				WHEN (SELECT COUNT(*) FROM team_members WHERE team_id = $1) >= max_size THEN 'full'
+				ELSE 'open'
+			END
+		WHERE id = $1`,
+		teamID,
+	)
+	return err
+}
+
+func (p *PostgresDB) CreateTeam(team *api.Team, ownerProfileID string) error {
+	teamID := uuid.New()
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO teams (id, name, description, owner_id, required_skills, focus, max_size, status)
+		VALUES ($1, $2, $3, $4, $5::text[], $6::text[], $7, $8)`,
+		teamID,
+		team.Name,
+		team.Description,
+		team.OwnerID,
+		pq.Array(team.RequiredSkills),
+		pq.Array(team.Focus),
+		team.MaxSize,
+		team.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating team: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO team_members (team_id, profile_id, role)
+		VALUES ($1, $2, $3)`,
+		teamID,
+		ownerProfileID,
+		api.TeamRoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("error adding owner to team: %w", err)
+	}
+
+	if err := refreshTeamStatus(tx, teamID); err != nil {
+		return err
+	}
+
+	created, err := scanTeam(tx.QueryRow(`SELECT `+teamColumns+` FROM teams t WHERE t.id = $1`, teamID))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*team = created
+	return nil
+}
+
+func (p *PostgresDB) GetTeam(id string) (api.Team, error) {
+	teamID, err := uuid.Parse(id)
+	if err != nil {
+		return api.Team{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	team, err := scanTeam(p.db.QueryRow(`SELECT `+teamColumns+` FROM teams t WHERE t.id = $1`, teamID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.Team{}, apperr.NotFound("team not found")
+		}
+		return api.Team{}, fmt.Errorf("error getting team: %w", err)
+	}
+
+	return team, nil
+}
+
+func (p *PostgresDB) UpdateTeam(id string, team *api.Team) error {
+	teamID, err := uuid.Parse(id)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the team like addTeamMember, so that no member joins between the
+	// count and the update
+	err = tx.QueryRow(`SELECT id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&teamID)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("team not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	var members int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM team_members WHERE team_id = $1`, teamID).Scan(&members); err != nil {
+		return err
+	}
+	if team.MaxSize < members {
+		return apperr.Conflict("the team already has %d members, more than max_size", members)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE teams
+		SET name = $1,
+			description = $2,
+			required_skills = $3,
+			focus = $4,
+			max_size = $5,
+			status = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7`,
+		team.Name,
+		team.Description,
+		pq.Array(team.RequiredSkills),
+		pq.Array(team.Focus),
+		team.MaxSize,
+		team.Status,
+		teamID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating team: %w", err)
+	}
+
+	if err := refreshTeamStatus(tx, teamID); err != nil {
+		return err
+	}
+
+	updated, err := scanTeam(tx.QueryRow(`SELECT `+teamColumns+` FROM teams t WHERE t.id = $1`, teamID))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*team = updated
+	return nil
+}
+
+func (p *PostgresDB) DeleteTeam(id string) error {
+	teamID, err := uuid.Parse(id)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	result, err := p.db.Exec(`DELETE FROM teams WHERE id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("error deleting team: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.NotFound("team not found")
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) SearchTeams(filter api.TeamSearchFilters, opts api.ListOptions) (api.TeamPage, error) {
+	where := "1=1"
+
+	var params []interface{}
+	paramCount := 1
+
+	if len(filter.Skills) > 0 {
+		where += fmt.Sprintf(" AND t.required_skills %s $%d", arrayOperator(filter.SkillsMatch), paramCount)
+		params = append(params, pq.Array(filter.Skills))
+		paramCount++
+	}
+
+	if len(filter.Focus) > 0 {
+		where += fmt.Sprintf(" AND t.focus %s $%d", arrayOperator(filter.FocusMatch), paramCount)
+		params = append(params, pq.Array(filter.Focus))
+		paramCount++
+	}
+
+	if filter.Status != "" {
+		where += fmt.Sprintf(" AND t.status = $%d", paramCount)
+		params = append(params, filter.Status)
+		paramCount++
+	}
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM teams t WHERE `+where, params...).Scan(&total); err != nil {
+		return api.TeamPage{}, fmt.Errorf("error counting teams: %w", err)
+	}
+
+	pageSQL, params, err := pageClause("t.", params, opts)
+	if err != nil {
+		return api.TeamPage{}, err
+	}
+
+	rows, err := p.db.Query(`SELECT `+teamColumns+` FROM teams t WHERE `+where+pageSQL, params...)
+	if err != nil {
+		return api.TeamPage{}, fmt.Errorf("error searching teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []api.Team
+
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return api.TeamPage{}, err
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return api.TeamPage{}, err
+	}
+
+	return api.NewTeamPage(opts, teams, total), nil
+}
+
+func (p *PostgresDB) GetTeamMembers(teamID string) ([]api.TeamMember, error) {
+	id, err := uuid.Parse(teamID)
+	if err != nil {
+		return nil, apperr.InvalidInput("invalid ID format")
+	}
+
+	query := `
+		SELECT m.team_id, m.profile_id, sp.name, m.role, m.joined_at
+		FROM team_members m
+		JOIN student_profiles sp ON sp.id = m.profile_id
+		WHERE m.team_id = $1
+		ORDER BY m.joined_at`
+
+	rows, err := p.db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []api.TeamMember{}
+
+	for rows.Next() {
+		var member api.TeamMember
+		if err := rows.Scan(&member.TeamID, &member.ProfileID, &member.Name, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (p *PostgresDB) RemoveTeamMember(teamID string, profileID string) error {
+	tid, err := uuid.Parse(teamID)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+	pid, err := uuid.Parse(profileID)
+	if err != nil {
+		return apperr.InvalidInput("invalid ID format")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var role string
+	err = tx.QueryRow(`
+		SELECT role FROM team_members
+		WHERE team_id = $1 AND profile_id = $2
+		FOR UPDATE`,
+		tid, pid,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("team member not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if role == api.TeamRoleOwner {
+		return apperr.Conflict("team owner can't leave the team")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM team_members WHERE team_id = $1 AND profile_id = $2`, tid, pid); err != nil {
+		return fmt.Errorf("error removing team member: %w", err)
+	}
+
+	if err := refreshTeamStatus(tx, tid); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,298 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rizkyswandy/TeamSeekerBackend/api"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// Pending requests past their expiry are reported as expired even before
+// a transition stores that state
+const teamRequestStatus = `CASE WHEN r.status = 'pending' AND r.expires_at < CURRENT_TIMESTAMP
+			THEN 'expired' ELSE r.status END`
+
+const teamRequestColumns = `r.id, r.team_id, t.name, r.profile_id, r.kind, ` + teamRequestStatus + `,
+			r.message, r.created_by, r.expires_at, r.created_at, r.updated_at`
+
+func scanTeamRequest(row rowScanner) (api.TeamRequest, error) {
+	var req api.TeamRequest
+
+	err := row.Scan(
+		&req.ID,
+		&req.TeamID,
+		&req.TeamName,
+		&req.ProfileID,
+		&req.Kind,
+		&req.Status,
+		&req.Message,
+		&req.CreatedBy,
+		&req.ExpiresAt,
+		&req.CreatedAt,
+		&req.UpdatedAt,
+	)
+	if err != nil {
+		return api.TeamRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (p *PostgresDB) CreateTeamRequest(req *api.TeamRequest) error {
+	requestID := uuid.New()
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var isMember bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM team_members WHERE team_id = $1 AND profile_id = $2)`,
+		req.TeamID, req.ProfileID,
+	).Scan(&isMember)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return apperr.Conflict("already a team member")
+	}
+
+	// Expired requests must not block new ones through the unique index
+	_, err = tx.Exec(`
+		UPDATE team_requests
+		SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE team_id = $1 AND profile_id = $2 AND status = 'pending' AND expires_at < CURRENT_TIMESTAMP`,
+		req.TeamID, req.ProfileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO team_requests (id, team_id, profile_id, kind, status, message, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP + $8 * INTERVAL '1 second')`,
+		requestID,
+		req.TeamID,
+		req.ProfileID,
+		req.Kind,
+		api.RequestPending,
+		req.Message,
+		req.CreatedBy,
+		int64(api.TeamRequestTTL.Seconds()),
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return apperr.Conflict("team request already pending")
+		}
+		return fmt.Errorf("error creating team request: %w", err)
+	}
+
+	created, err := scanTeamRequest(tx.QueryRow(`
+		SELECT `+teamRequestColumns+`
+		FROM team_requests r JOIN teams t ON t.id = r.team_id
+		WHERE r.id = $1`, requestID))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*req = created
+	return nil
+}
+
+func (p *PostgresDB) GetTeamRequest(id string) (api.TeamRequest, error) {
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return api.TeamRequest{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	req, err := scanTeamRequest(p.db.QueryRow(`
+		SELECT `+teamRequestColumns+`
+		FROM team_requests r JOIN teams t ON t.id = r.team_id
+		WHERE r.id = $1`, requestID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return api.TeamRequest{}, apperr.NotFound("team request not found")
+		}
+		return api.TeamRequest{}, fmt.Errorf("error getting team request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (p *PostgresDB) TransitionTeamRequest(id string, status string) (api.TeamRequest, error) {
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return api.TeamRequest{}, apperr.InvalidInput("invalid ID format")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return api.TeamRequest{}, err
+	}
+	defer tx.Rollback()
+
+	var current, teamID, profileID string
+	var expired bool
+	err = tx.QueryRow(`
+		SELECT status, expires_at < CURRENT_TIMESTAMP, team_id, profile_id
+		FROM team_requests
+		WHERE id = $1
+		FOR UPDATE`,
+		requestID,
+	).Scan(&current, &expired, &teamID, &profileID)
+	if err == sql.ErrNoRows {
+		return api.TeamRequest{}, apperr.NotFound("team request not found")
+	}
+	if err != nil {
+		return api.TeamRequest{}, err
+	}
+
+	if current == status {
+		tx.Rollback()
+		return p.GetTeamRequest(id)
+	}
+
+	if current == api.RequestPending && expired {
+		_, err := tx.Exec(`
+			UPDATE team_requests
+			SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1`,
+			requestID,
+		)
+		if err != nil {
+			return api.TeamRequest{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return api.TeamRequest{}, err
+		}
+		return api.TeamRequest{}, apperr.Conflict("team request expired")
+	}
+
+	if current != api.RequestPending {
+		return api.TeamRequest{}, apperr.Conflict("team request already %s", current)
+	}
+
+	if status == api.RequestAccepted {
+		if err := addTeamMember(tx, teamID, profileID); err != nil {
+			return api.TeamRequest{}, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE team_requests
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`,
+		status, requestID,
+	)
+	if err != nil {
+		return api.TeamRequest{}, fmt.Errorf("error updating team request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.TeamRequest{}, err
+	}
+
+	return p.GetTeamRequest(id)
+}
+
+// addTeamMember adds a profile to a team, holding a lock on the team row so
+// that concurrent acceptances can't push it past its max size
+func addTeamMember(tx *sql.Tx, teamID, profileID string) error {
+	var maxSize int
+	var status string
+	err := tx.QueryRow(`SELECT max_size, status FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&maxSize, &status)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("team not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == api.TeamStatusClosed {
+		return apperr.Conflict("team is closed")
+	}
+
+	var members int
+	var isMember bool
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(BOOL_OR(profile_id = $2), false)
+		FROM team_members
+		WHERE team_id = $1`,
+		teamID, profileID,
+	).Scan(&members, &isMember)
+	if err != nil {
+		return err
+	}
+
+	if isMember {
+		return nil
+	}
+
+	if members >= maxSize {
+		return apperr.Conflict("team is full")
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO team_members (team_id, profile_id, role)
+		VALUES ($1, $2, $3)`,
+		teamID, profileID, api.TeamRoleMember,
+	)
+	if err != nil {
+		return fmt.Errorf("error adding team member: %w", err)
+	}
+
+	return refreshTeamStatus(tx, teamID)
+}
+
+func (p *PostgresDB) ListTeamRequests(filter api.TeamRequestFilter) ([]api.TeamRequest, error) {
+	// The caller is on the receiving end of invitations to their profile and
+	// of join requests to their teams, and the sending end otherwise
+	profileKind, ownerKind := api.TeamRequestInvitation, api.TeamRequestJoin
+	if filter.Direction == api.DirectionOutgoing {
+		profileKind, ownerKind = api.TeamRequestJoin, api.TeamRequestInvitation
+	}
+
+	query := `
+		SELECT ` + teamRequestColumns + `
+		FROM team_requests r JOIN teams t ON t.id = r.team_id
+		WHERE ((r.kind = $1 AND r.profile_id::text = $2) OR (r.kind = $3 AND t.owner_id::text = $4))`
+	params := []interface{}{profileKind, filter.ProfileID, ownerKind, filter.OwnerID}
+
+	if filter.Status != "" {
+		query += ` AND ` + teamRequestStatus + ` = $5`
+		params = append(params, filter.Status)
+	}
+
+	query += ` ORDER BY r.created_at DESC`
+
+	rows, err := p.db.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing team requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []api.TeamRequest{}
+
+	for rows.Next() {
+		req, err := scanTeamRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
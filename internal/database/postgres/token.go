@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func (p *PostgresDB) CreateRefreshToken(token *types.RefreshToken) error {
+	if token.ID == "" {
+		token.ID = uuid.NewString()
+	}
+
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`
+
+	err := p.db.QueryRow(
+		query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) GetRefreshTokenByHash(hash string) (types.RefreshToken, error) {
+	var token types.RefreshToken
+	var replacedBy sql.NullString
+	var revokedAt sql.NullTime
+
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	err := p.db.QueryRow(query, hash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&revokedAt,
+		&replacedBy,
+		&token.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return types.RefreshToken{}, apperr.NotFound("refresh token not found")
+	}
+
+	if err != nil {
+		return types.RefreshToken{}, fmt.Errorf("error getting refresh token: %w", err)
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.ReplacedBy = replacedBy.String
+
+	return token, nil
+}
+
+// RotateRefreshToken revokes oldID and stores next in one transaction. It
+// fails with "refresh token already used" if oldID was revoked concurrently.
+func (p *PostgresDB) RotateRefreshToken(oldID string, next *types.RefreshToken) error {
+	if next.ID == "" {
+		next.ID = uuid.NewString()
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP,
+			replaced_by = $1
+		WHERE id = $2 AND revoked_at IS NULL`,
+		next.ID,
+		oldID,
+	)
+	if err != nil {
+		return fmt.Errorf("error rotating refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperr.Conflict("refresh token already used")
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`,
+		next.ID,
+		next.UserID,
+		next.FamilyID,
+		next.TokenHash,
+		next.ExpiresAt,
+	).Scan(&next.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *PostgresDB) RevokeRefreshTokenFamily(familyID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := p.db.Exec(query, familyID); err != nil {
+		return fmt.Errorf("error revoking refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresDB) RevokeUserRefreshTokens(userID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := p.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("error revoking refresh tokens of user: %w", err)
+	}
+
+	return nil
+}
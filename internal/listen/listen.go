@@ -0,0 +1,92 @@
+// Package listen opens the listeners the server accepts connections on.
+// Addresses are written as
+//
+//	:3000, host:3000           TCP, dual-stack IPv4/IPv6 for wildcard hosts
+//	tcp4://0.0.0.0:3000        IPv4 only
+//	tcp6://[::]:3000           IPv6 only
+//	unix:///run/app/app.sock   Unix domain socket
+//	systemd                    sockets passed by systemd socket activation
+package listen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// Systemd is the address of the sockets passed by systemd
+const Systemd = "systemd"
+
+// Listen opens the listeners of every address. On error the listeners
+// opened so far are closed again. socketMode is applied to Unix sockets.
+func Listen(addrs []string, socketMode fs.FileMode) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range addrs {
+		opened, err := listen(addr, socketMode)
+		if err != nil {
+			for _, ln := range listeners {
+				ln.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, opened...)
+	}
+	return listeners, nil
+}
+
+func listen(addr string, socketMode fs.FileMode) ([]net.Listener, error) {
+	if addr == Systemd {
+		return systemdListeners()
+	}
+
+	network, address := "tcp", addr
+	if scheme, rest, ok := strings.Cut(addr, "://"); ok {
+		network, address = scheme, rest
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		ln, err := net.Listen(network, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return []net.Listener{ln}, nil
+	case "unix":
+		ln, err := unixSocket(address, socketMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return []net.Listener{ln}, nil
+	default:
+		return nil, fmt.Errorf("unsupported listen address %q, use host:port, tcp4://, tcp6://, unix:// or systemd", addr)
+	}
+}
+
+// unixSocket replaces a socket left behind by a process that didn't shut
+// down cleanly; any other file at path is an error
+func unixSocket(path string, mode fs.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is removed again when the listener is closed
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,160 @@
+// Package logging sets up structured logging with log/slog. Every package
+// logs through its own logger from New, whose level can be set separately,
+// e.g. "info,postgres=debug". Records logged with a context carry the
+// request ID stored in it.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Levels are the minimum levels of records, by package
+type Levels struct {
+	Default  slog.Level
+	Packages map[string]slog.Level
+}
+
+// Level returns the minimum level of records of pkg
+func (l Levels) Level(pkg string) slog.Level {
+	if level, ok := l.Packages[pkg]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// ParseLevels parses a comma-separated list of a default level and
+// package=level pairs, like "info,postgres=debug,middleware=warn". The
+// levels are debug, info, warn and error.
+func ParseLevels(s string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo, Packages: map[string]slog.Level{}}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pkg, name, ok := strings.Cut(item, "=")
+		if !ok {
+			name = pkg
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return Levels{}, fmt.Errorf("invalid log level %q, use debug, info, warn or error", name)
+		}
+
+		if ok {
+			levels.Packages[strings.TrimSpace(pkg)] = level
+		} else {
+			levels.Default = level
+		}
+	}
+	return levels, nil
+}
+
+type config struct {
+	handler slog.Handler
+	levels  Levels
+}
+
+// current is replaced by Configure. Until then records are written as text
+// to stderr at info level.
+var current atomic.Pointer[config]
+
+func init() {
+	current.Store(&config{
+		handler: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		levels:  Levels{Default: slog.LevelInfo},
+	})
+}
+
+// Configure writes all records to w as "json" or "text" from now on. It
+// also routes the standard log package and slog's default logger through
+// the "default" package, so that their output is structured as well.
+func Configure(w io.Writer, format string, levels Levels) error {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q, use json or text", format)
+	}
+
+	current.Store(&config{handler: handler, levels: levels})
+
+	slog.SetDefault(New("default"))
+	return nil
+}
+
+// New returns the logger of pkg. It follows later calls to Configure, as
+// long as no attributes or groups were added to it before.
+func New(pkg string) *slog.Logger {
+	return slog.New(&packageHandler{pkg: pkg})
+}
+
+// StdLogger returns a logger of the log package that writes records of pkg
+// at level, for APIs like http.Server.ErrorLog
+func StdLogger(pkg string, level slog.Level) *log.Logger {
+	return slog.NewLogLogger(&packageHandler{pkg: pkg}, level)
+}
+
+// packageHandler filters records by the level of its package. handler is
+// nil until attributes or groups are added, the configured handler is
+// used then.
+type packageHandler struct {
+	pkg     string
+	handler slog.Handler
+}
+
+func (h *packageHandler) target() slog.Handler {
+	if h.handler != nil {
+		return h.handler
+	}
+	return current.Load().handler.WithAttrs([]slog.Attr{slog.String("package", h.pkg)})
+}
+
+func (h *packageHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= current.Load().levels.Level(h.pkg)
+}
+
+func (h *packageHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.target().Handle(ctx, record)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &packageHandler{pkg: h.pkg, handler: h.target().WithAttrs(attrs)}
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return &packageHandler{pkg: h.pkg, handler: h.target().WithGroup(name)}
+}
+
+type contextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request, which
+// is added to every record logged with it
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or ""
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,64 @@
+// Package problem writes RFC 7807 problem details responses. It is shared by
+// the API handlers and the middleware so that every error the server returns
+// has the same shape.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Type is "about:blank" unless
+// a problem has more specific semantics, in which case Title is still the
+// HTTP status text.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why one field of a request was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// New returns a problem for status with the given detail message
+func New(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Write sends p as the response to r, filling in the instance and the
+// request ID stored in its context.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = logging.RequestID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Error is a drop-in replacement for http.Error that writes a problem
+// without field errors
+func Error(w http.ResponseWriter, r *http.Request, detail string, status int) {
+	Write(w, r, New(status, detail))
+}
@@ -0,0 +1,204 @@
+// Package validate checks structs against rules declared in `validate` struct
+// tags, e.g.
+//
+//	Name     string   `json:"name" validate:"required,max=100"`
+//	Semester int      `json:"semester" validate:"min=1,max=14"`
+//	Skills   []string `json:"skills" validate:"max=20,itemmax=50"`
+//
+// Every violation is collected and returned as one *apperr.ValidationError
+// with fields named after their JSON keys.
+//
+// Rules:
+//   - required: strings must not be blank, numbers not zero, slices not empty
+//   - min=N, max=N: value of ints, length in characters of strings, number of
+//     items of slices
+//   - itemmax=N: maximum length of every item of a string slice; items can't
+//     be blank either
+//   - email: a plausible email address
+//   - password: the password policy, see Password
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+// Password policy. bcrypt ignores everything after 72 bytes, so longer
+// passwords would give a false sense of security.
+const (
+	PasswordMinLength = 8
+	PasswordMaxBytes  = 72
+)
+
+// Struct validates the exported fields of the struct v points to. It panics
+// on malformed tags since those are programming errors.
+func Struct(v interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		panic("validate: Struct called with a non-struct value")
+	}
+
+	errs := &apperr.ValidationError{}
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		name := jsonName(field)
+		for _, rule := range strings.Split(tag, ",") {
+			if msg := check(value.Field(i), rule); msg != "" {
+				errs.Add(name, "%s %s", name, msg)
+				// Later rules usually make no sense once one failed
+				break
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// Password checks a password against the policy and returns what is wrong
+// with it, or "" if it is acceptable
+func Password(password string) string {
+	if utf8.RuneCountInString(password) < PasswordMinLength {
+		return fmt.Sprintf("must be at least %d characters long", PasswordMinLength)
+	}
+	if len(password) > PasswordMaxBytes {
+		return fmt.Sprintf("can't be longer than %d bytes", PasswordMaxBytes)
+	}
+
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return "must contain at least one letter and one digit"
+	}
+
+	return ""
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// check applies one rule to a value and returns the violation, or "" if
+// there is none
+func check(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	switch name {
+	case "required":
+		if isBlank(v) {
+			return "is required"
+		}
+	case "min":
+		n := intArg(rule, arg)
+		if v.Kind() == reflect.Int {
+			if v.Int() < int64(n) {
+				return fmt.Sprintf("must be at least %d", n)
+			}
+		} else if size(v) < n {
+			return fmt.Sprintf("must have at least %d %s", n, unit(v))
+		}
+	case "max":
+		n := intArg(rule, arg)
+		if v.Kind() == reflect.Int {
+			if v.Int() > int64(n) {
+				return fmt.Sprintf("must be at most %d", n)
+			}
+		} else if size(v) > n {
+			return fmt.Sprintf("can't have more than %d %s", n, unit(v))
+		}
+	case "itemmax":
+		n := intArg(rule, arg)
+		for _, item := range stringItems(v) {
+			if strings.TrimSpace(item) == "" {
+				return "can't contain blank items"
+			}
+			if utf8.RuneCountInString(item) > n {
+				return fmt.Sprintf("items can't be longer than %d characters", n)
+			}
+		}
+	case "email":
+		if v.String() != "" && !isEmail(v.String()) {
+			return "must be a valid email address"
+		}
+	case "password":
+		if v.String() != "" {
+			return Password(v.String())
+		}
+	default:
+		panic("validate: unknown rule " + rule)
+	}
+
+	return ""
+}
+
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func size(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+func unit(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
+
+func stringItems(v reflect.Value) []string {
+	items, _ := v.Interface().([]string)
+	return items
+}
+
+func intArg(rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic("validate: rule " + rule + " needs an integer argument")
+	}
+	return n
+}
+
+// isEmail accepts bare addresses with a dot in the domain, which is what we
+// can reasonably send mail to
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	_, domain, _ := strings.Cut(s, "@")
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
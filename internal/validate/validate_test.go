@@ -0,0 +1,150 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/apperr"
+)
+
+type testStruct struct {
+	Name     string   `json:"name" validate:"required,max=5"`
+	Email    string   `json:"email,omitempty" validate:"email"`
+	Semester int      `json:"semester" validate:"min=1,max=14"`
+	Skills   []string `json:"skills" validate:"max=2,itemmax=3"`
+	Password string   `validate:"password"`
+	Tags     []string `json:"tags" validate:"required"`
+	Ignored  string   `json:"ignored"`
+	hidden   string   `validate:"required"`
+}
+
+func valid() testStruct {
+	return testStruct{Name: "Ann", Email: "ann@example.com", Semester: 3, Skills: []string{"Go"}, Tags: []string{"x"}}
+}
+
+func TestStructValid(t *testing.T) {
+	v := valid()
+	if err := Struct(&v); err != nil {
+		t.Errorf("valid struct rejected: %v", err)
+	}
+	// Values work as well as pointers
+	if err := Struct(v); err != nil {
+		t.Errorf("valid struct value rejected: %v", err)
+	}
+}
+
+func TestStructRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(*testStruct)
+		field string
+		msg   string
+	}{
+		{"required blank", func(v *testStruct) { v.Name = "  " }, "name", "name is required"},
+		{"max characters", func(v *testStruct) { v.Name = "Annabel" }, "name", "name can't have more than 5 characters"},
+		{"max counts runes", func(v *testStruct) { v.Name = "ééééé" }, "", ""},
+		{"email", func(v *testStruct) { v.Email = "ann" }, "email", "email must be a valid email address"},
+		{"email with name", func(v *testStruct) { v.Email = "Ann <ann@example.com>" }, "email", "email must be a valid email address"},
+		{"email without dot", func(v *testStruct) { v.Email = "ann@localhost" }, "email", "email must be a valid email address"},
+		{"email trailing dot", func(v *testStruct) { v.Email = "ann@example." }, "email", "email must be a valid email address"},
+		{"email optional", func(v *testStruct) { v.Email = "" }, "", ""},
+		{"min int", func(v *testStruct) { v.Semester = 0 }, "semester", "semester must be at least 1"},
+		{"max int", func(v *testStruct) { v.Semester = 15 }, "semester", "semester must be at most 14"},
+		{"max items", func(v *testStruct) { v.Skills = []string{"a", "b", "c"} }, "skills", "skills can't have more than 2 items"},
+		{"itemmax", func(v *testStruct) { v.Skills = []string{"Rust"} }, "skills", "skills items can't be longer than 3 characters"},
+		{"blank item", func(v *testStruct) { v.Skills = []string{" "} }, "skills", "skills can't contain blank items"},
+		{"required slice", func(v *testStruct) { v.Tags = nil }, "tags", "tags is required"},
+		{"password", func(v *testStruct) { v.Password = "short1" }, "Password", "Password must be at least 8 characters long"},
+		{"unexported ignored", func(v *testStruct) { v.hidden = "" }, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := valid()
+			tt.edit(&v)
+			err := Struct(&v)
+
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+
+			var validation *apperr.ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("error = %v, want a *apperr.ValidationError", err)
+			}
+			if !errors.Is(err, apperr.ErrInvalidInput) {
+				t.Errorf("error doesn't match apperr.ErrInvalidInput")
+			}
+			if len(validation.Fields) != 1 || validation.Fields[0].Field != tt.field || validation.Fields[0].Message != tt.msg {
+				t.Errorf("fields = %+v, want %s: %q", validation.Fields, tt.field, tt.msg)
+			}
+		})
+	}
+}
+
+func TestStructReportsEveryField(t *testing.T) {
+	v := testStruct{Name: "", Semester: 20, Skills: []string{"a", "b", "c"}}
+	err := Struct(&v)
+
+	var validation *apperr.ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("error = %v, want a *apperr.ValidationError", err)
+	}
+
+	var fields []string
+	for _, f := range validation.Fields {
+		fields = append(fields, f.Field)
+	}
+	if strings.Join(fields, ",") != "name,semester,skills,tags" {
+		t.Errorf("fields = %v, want one error for each of name, semester, skills and tags", fields)
+	}
+}
+
+func TestStructPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"non-struct", "text"},
+		{"unknown rule", &struct {
+			A string `validate:"nope"`
+		}{}},
+		{"bad argument", &struct {
+			A string `validate:"max=many"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+			Struct(tt.v)
+		})
+	}
+}
+
+func TestPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"password1", ""},
+		{"pässwörd1", ""},
+		{"short1", "must be at least 8 characters long"},
+		{"passwordonly", "must contain at least one letter and one digit"},
+		{"12345678", "must contain at least one letter and one digit"},
+		{strings.Repeat("a1", 36), ""},
+		{strings.Repeat("a1", 36) + "a", "can't be longer than 72 bytes"},
+		{strings.Repeat("é", 36) + "1", "can't be longer than 72 bytes"},
+	}
+	for _, tt := range tests {
+		if got := Password(tt.password); got != tt.want {
+			t.Errorf("Password(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
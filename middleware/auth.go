@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// Claims is the payload of the access tokens issued by the auth handlers.
+type Claims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Auth verifies the "Authorization: Bearer <token>" header against the given
+// HMAC secret and stores the authenticated user in the request context.
+// Missing, expired or tampered tokens are rejected with 401.
+func Auth(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, tokenString, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(tokenString) == "" {
+				unauthorized(w, r, "Missing bearer token")
+				return
+			}
+
+			claims := &Claims{}
+			_, err := jwt.ParseWithClaims(
+				strings.TrimSpace(tokenString),
+				claims,
+				func(token *jwt.Token) (interface{}, error) {
+					return secret, nil
+				},
+				jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+				jwt.WithExpirationRequired(),
+			)
+			if err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					unauthorized(w, r, "Token expired")
+					return
+				}
+				unauthorized(w, r, "Invalid token")
+				return
+			}
+
+			if claims.UserID == "" {
+				unauthorized(w, r, "Invalid token")
+				return
+			}
+
+			user := types.User{
+				ID:    claims.UserID,
+				Email: claims.Email,
+				Role:  claims.Role,
+			}
+
+			logUser(r.Context(), user.ID)
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user types.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the user stored by Auth, if any.
+func UserFromContext(ctx context.Context) (types.User, bool) {
+	user, ok := ctx.Value(userContextKey).(types.User)
+	return user, ok
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	problem.Error(w, r, message, http.StatusUnauthorized)
+}
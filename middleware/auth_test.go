@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+var testSecret = []byte("test-secret-test-secret-test-secret")
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func testClaims(expires time.Duration) Claims {
+	return Claims{
+		UserID: "user-1",
+		Email:  "user@example.com",
+		Role:   types.RoleStudent,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expires)),
+		},
+	}
+}
+
+func TestAuth(t *testing.T) {
+	valid := signToken(t, jwt.SigningMethodHS256, testSecret, testClaims(time.Hour))
+	noExpiry := testClaims(time.Hour)
+	noExpiry.ExpiresAt = nil
+	noUser := testClaims(time.Hour)
+	noUser.UserID = ""
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		detail string
+	}{
+		{"valid", "Bearer " + valid, http.StatusOK, ""},
+		{"lower-case scheme", "bearer " + valid, http.StatusOK, ""},
+		{"no header", "", http.StatusUnauthorized, "Missing bearer token"},
+		{"no bearer prefix", valid, http.StatusUnauthorized, "Missing bearer token"},
+		{"other scheme", "Basic " + valid, http.StatusUnauthorized, "Missing bearer token"},
+		{"empty token", "Bearer  ", http.StatusUnauthorized, "Missing bearer token"},
+		{"malformed", "Bearer not-a-jwt", http.StatusUnauthorized, "Invalid token"},
+		{"expired", "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, testClaims(-time.Minute)), http.StatusUnauthorized, "Token expired"},
+		{"wrong secret", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("another-secret"), testClaims(time.Hour)), http.StatusUnauthorized, "Invalid token"},
+		{"wrong algorithm", "Bearer " + signToken(t, jwt.SigningMethodHS512, testSecret, testClaims(time.Hour)), http.StatusUnauthorized, "Invalid token"},
+		{"unsigned", "Bearer " + signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testClaims(time.Hour)), http.StatusUnauthorized, "Invalid token"},
+		{"no expiry", "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, noExpiry), http.StatusUnauthorized, "Invalid token"},
+		{"no user", "Bearer " + signToken(t, jwt.SigningMethodHS256, testSecret, noUser), http.StatusUnauthorized, "Invalid token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got types.User
+			handler := Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = UserFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest("GET", "/api/profiles", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusOK {
+				if got.ID != "user-1" || got.Role != types.RoleStudent {
+					t.Errorf("user = %+v, want the token's user", got)
+				}
+				return
+			}
+
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+			var p problem.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Detail != tt.detail {
+				t.Errorf("detail = %q, want %q", p.Detail, tt.detail)
+			}
+		})
+	}
+}
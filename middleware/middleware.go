@@ -1,28 +1,121 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
 )
 
+var logger = logging.New("middleware")
+
+const logEntryContextKey contextKey = "logEntry"
+
+// logEntry collects what handlers further down learn about a request, like
+// the user Auth authenticated, for the line Logger writes
+type logEntry struct {
+	userID string
+}
+
+// Logger logs every request once it is completed, with the status and size
+// of the response and who sent it. Server errors are logged as errors.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		start := time.Now()
-		log.Printf("Started %s %s", request.Method, request.URL.Path)
+		ctx := request.Context()
+		logger.DebugContext(ctx, "Request started", "method", request.Method, "path", request.URL.Path)
 
-		next.ServeHTTP(writer, request)
+		entry := &logEntry{}
+		recorder := &responseRecorder{ResponseWriter: writer, status: http.StatusOK}
+		next.ServeHTTP(recorder, request.WithContext(context.WithValue(ctx, logEntryContextKey, entry)))
 
-		log.Printf("Completed %s %s in %v", request.Method, request.URL.Path, time.Since(start))
+		attrs := []slog.Attr{
+			slog.String("method", request.Method),
+			slog.String("path", request.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", clientIP(request)),
+		}
+		if entry.userID != "" {
+			attrs = append(attrs, slog.String("user_id", entry.userID))
+		}
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(ctx, level, "Request completed", attrs...)
 	})
 }
 
+// logUser records the authenticated user of the request for Logger
+func logUser(ctx context.Context, userID string) {
+	if entry, ok := ctx.Value(logEntryContextKey).(*logEntry); ok {
+		entry.userID = userID
+	}
+}
+
+// clientIP is the address of the peer, or of the client that a proxy on the
+// same host, e.g. nginx, forwarded the request for. Peers on a Unix socket
+// have no IP address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if ip := net.ParseIP(host); ip == nil || ip.IsLoopback() {
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return realIP
+		}
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			hops := strings.Split(forwarded, ",")
+			return strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+	return host
+}
+
+// responseRecorder remembers the status and counts the bytes of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // TO-DO: For production scale, we need to enhance it more secure by declaring explicitly allowed origins
 func CORS(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Access-Control-Allow-Origin", "*")
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-Request-ID")
+        w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
         
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
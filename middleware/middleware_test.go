@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		forwarded  string
+		want       string
+	}{
+		{"direct peer", "203.0.113.7:51234", "", "", "203.0.113.7"},
+		{"direct IPv6 peer", "[2001:db8::1]:443", "", "", "2001:db8::1"},
+		{"remote peer can't claim another address", "203.0.113.7:51234", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"loopback without proxy headers", "127.0.0.1:8080", "", "", "127.0.0.1"},
+		{"loopback proxy, X-Real-IP", "127.0.0.1:8080", "198.51.100.1", "", "198.51.100.1"},
+		{"loopback IPv6 proxy", "[::1]:8080", "198.51.100.1", "", "198.51.100.1"},
+		{"X-Real-IP wins", "127.0.0.1:8080", "198.51.100.1", "198.51.100.2", "198.51.100.1"},
+		{"last X-Forwarded-For hop", "127.0.0.1:8080", "", "10.0.0.1, 198.51.100.2", "198.51.100.2"},
+		{"unix socket proxy", "@", "198.51.100.1", "", "198.51.100.1"},
+		{"unix socket without headers", "@", "", "", "@"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
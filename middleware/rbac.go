@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+// RequirePermission lets the request through only if the authenticated
+// user's role grants perm. It must run after Auth.
+func RequirePermission(perm types.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				unauthorized(w, r, "Authentication required")
+				return
+			}
+
+			if !types.HasPermission(user.Role, perm) {
+				problem.Error(w, r, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rizkyswandy/TeamSeekerBackend/types"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *types.User
+		perm   types.Permission
+		status int
+	}{
+		{"granted", &types.User{ID: "1", Role: types.RoleStudent}, types.PermProfileRead, http.StatusOK},
+		{"legacy user role", &types.User{ID: "1", Role: types.RoleLegacyUser}, types.PermProfileRead, http.StatusOK},
+		{"admin", &types.User{ID: "1", Role: types.RoleAdmin}, types.PermUserManage, http.StatusOK},
+		{"denied", &types.User{ID: "1", Role: types.RoleStudent}, types.PermUserManage, http.StatusForbidden},
+		{"unknown role", &types.User{ID: "1", Role: "root"}, types.PermProfileRead, http.StatusForbidden},
+		{"not authenticated", nil, types.PermProfileRead, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := RequirePermission(tt.perm)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.user != nil {
+				r = r.WithContext(WithUser(r.Context(), *tt.user))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if called != (tt.status == http.StatusOK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the IDs taken from clients to what is safe to log
+// and send back
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:+=/-]{1,128}$`)
+
+// RequestID gives every request an ID, which is sent back in the
+// X-Request-ID header and added to the logs and problem responses of the
+// request. An ID set by the client or a proxy is kept, a new one is
+// generated if there is none or it looks invalid.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/logging"
+	"github.com/rizkyswandy/TeamSeekerBackend/internal/problem"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"from client", "abc-123", true},
+		{"from proxy", "5f0c:1/2+3=4.x_y", true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"unsafe characters", "abc\ndef", false},
+		{"spaces", "abc def", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = logging.RequestID(r.Context())
+				problem.Error(w, r, "Nope", http.StatusTeapot)
+			}))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.header {
+				t.Errorf("request ID = %q, want %q", id, tt.header)
+			}
+			if !tt.keep {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("request ID = %q, want a new UUID", id)
+				}
+			}
+
+			// Handlers, their logs and problem responses see the same ID
+			if seen != id {
+				t.Errorf("context request ID = %q, want %q", seen, id)
+			}
+			var p problem.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.RequestID != id {
+				t.Errorf("problem request_id = %q, want %q", p.RequestID, id)
+			}
+		})
+	}
+}
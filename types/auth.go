@@ -1,16 +1,40 @@
 package types
 
+import "time"
+
+// LoginRequest only checks the shape of the credentials, the password policy
+// applies to new passwords
 type LoginRequest struct{
-	Email string `json:"email"`
-	Password string `json:"password"`
+	Email string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type RegisterRequest struct{
-	Email string `json:"email"`
-	Password string `json:"password"`
+	Email string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,password"`
 }
 
 type AuthResponse struct{
 	Token string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn int64 `json:"expires_in"`
 	User User `json:"user"`
+}
+
+type RefreshRequest struct{
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshToken is the server-side record of an opaque refresh token. Only the
+// SHA-256 hash of the token is stored. Tokens issued from the same login share
+// a FamilyID so that the whole chain can be revoked when reuse is detected.
+type RefreshToken struct{
+	ID string
+	UserID string
+	FamilyID string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	ReplacedBy string
+	CreatedAt time.Time
 }
\ No newline at end of file
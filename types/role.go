@@ -0,0 +1,98 @@
+package types
+
+const (
+	RoleStudent          = "student"
+	RoleTeamLead         = "team_lead"
+	RoleFacultyModerator = "faculty_moderator"
+	RoleAdmin            = "admin"
+
+	// RoleLegacyUser is the role given to accounts created before roles
+	// existed. It has the same permissions as RoleStudent.
+	RoleLegacyUser = "user"
+)
+
+type Permission string
+
+const (
+	PermProfileRead     Permission = "profile:read"
+	PermProfileWrite    Permission = "profile:write"
+	PermProfileModerate Permission = "profile:moderate"
+	PermProfileEndorse  Permission = "profile:endorse"
+	PermUserManage      Permission = "user:manage"
+	PermAccountManage   Permission = "account:manage"
+	PermTeamRead        Permission = "team:read"
+	PermTeamJoin        Permission = "team:join"
+	PermTeamManage      Permission = "team:manage"
+	PermTeamModerate    Permission = "team:moderate"
+	PermTaxonomyManage  Permission = "taxonomy:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleStudent: {
+		PermProfileRead,
+		PermProfileWrite,
+		PermProfileEndorse,
+		PermAccountManage,
+		PermTeamRead,
+		PermTeamJoin,
+	},
+	RoleTeamLead: {
+		PermProfileRead,
+		PermProfileWrite,
+		PermProfileEndorse,
+		PermAccountManage,
+		PermTeamRead,
+		PermTeamJoin,
+		PermTeamManage,
+	},
+	RoleFacultyModerator: {
+		PermProfileRead,
+		PermProfileWrite,
+		PermProfileModerate,
+		PermProfileEndorse,
+		PermAccountManage,
+		PermTeamRead,
+		PermTeamJoin,
+		PermTeamManage,
+		PermTeamModerate,
+		PermTaxonomyManage,
+	},
+	RoleAdmin: {
+		PermProfileRead,
+		PermProfileWrite,
+		PermProfileModerate,
+		PermProfileEndorse,
+		PermUserManage,
+		PermAccountManage,
+		PermTeamRead,
+		PermTeamJoin,
+		PermTeamManage,
+		PermTeamModerate,
+		PermTaxonomyManage,
+	},
+}
+
+// Roles lists every role that can be assigned to a user
+var Roles = []string{RoleStudent, RoleTeamLead, RoleFacultyModerator, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role string, perm Permission) bool {
+	if role == RoleLegacyUser {
+		role = RoleStudent
+	}
+
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}